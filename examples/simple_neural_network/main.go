@@ -25,12 +25,20 @@ func main() {
 	logger, _ := zap.NewProduction()
 	sugaredLogger := logger.Sugar()
 
+	sgd, err := optimizer.NewSGD(optimizer.SGDConfig{
+		LearningRate: 0.01,
+	})
+	if err != nil {
+		sugaredLogger.With(zap.Error(err)).Fatal("Failed to create optimizer")
+	}
+
 	net := nn.NewNeuralNetwork(
 		nn.NeuralNetworkConfig{
 			InputShape: 3,
 			Shape:      []int{3, 3, 3},
+			Backend:    nn.Float64Backend,
 		},
-		optimizer.SGD,
+		sgd,
 		loss.MeanSquaredError,
 	)
 
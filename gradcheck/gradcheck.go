@@ -0,0 +1,167 @@
+package gradcheck
+
+import (
+	"errors"
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+const (
+	defaultEpsilon      = 1e-6
+	defaultAbsTolerance = 1e-8
+	defaultRelTolerance = 1e-5
+)
+
+var (
+	ErrNoLeaves  = errors.New("no leaves to check")
+	ErrNilOutput = errors.New("function returned nil output")
+)
+
+// Func builds a scalar output from the given leaves; it is invoked repeatedly so must rebuild the graph from
+// the current data of the leaves on every call.
+type Func func(leaves []*nn.Value) *nn.Value
+
+type Config struct {
+	// Epsilon is the step used for the central finite difference; defaults to 1e-6.
+	Epsilon float64
+	// AbsTolerance is the absolute error always allowed for a leaf to pass, so that gradients near zero aren't
+	// failed by the noise of the finite difference; defaults to 1e-8.
+	AbsTolerance float64
+	// RelTolerance is the error allowed relative to the larger of the analytic & numeric gradients; defaults to
+	// 1e-5. A leaf passes if its absolute error is at most AbsTolerance + RelTolerance * max(|analytic|, |numeric|).
+	RelTolerance float64
+}
+
+// Result is the outcome of checking the gradient of a single leaf.
+type Result struct {
+	Index         int
+	Label         string
+	Analytic      float64
+	Numeric       float64
+	AbsoluteError float64
+	RelativeError float64
+	Passed        bool
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf(
+		"leaf %d: analytic=%.8f numeric=%.8f abs_err=%.3e rel_err=%.3e passed=%t",
+		r.Index, r.Analytic, r.Numeric, r.AbsoluteError, r.RelativeError, r.Passed,
+	)
+}
+
+type Report struct {
+	Results []Result
+}
+
+// Passed returns true if every leaf passed the check.
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if !res.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Failures returns the results of the leaves that did not pass the check.
+func (r *Report) Failures() []Result {
+	var out []Result
+	for _, res := range r.Results {
+		if !res.Passed {
+			out = append(out, res)
+		}
+	}
+
+	return out
+}
+
+// Check compares the gradient of f w.r.t each leaf computed via backpropagation against the gradient
+// estimated by central finite differences (f(x + h) - f(x - h)) / 2h.
+//
+// Any gradient already accumulated on the leaves is reset; their data is restored before returning.
+func Check(cfg Config, f Func, leaves []*nn.Value) (*Report, error) {
+	if len(leaves) == 0 {
+		return nil, ErrNoLeaves
+	}
+
+	epsilon := cfg.Epsilon
+	if epsilon <= 0 {
+		epsilon = defaultEpsilon
+	}
+
+	absTolerance := cfg.AbsTolerance
+	if absTolerance <= 0 {
+		absTolerance = defaultAbsTolerance
+	}
+
+	relTolerance := cfg.RelTolerance
+	if relTolerance <= 0 {
+		relTolerance = defaultRelTolerance
+	}
+
+	// Analytic pass.
+	for _, leaf := range leaves {
+		leaf.ZeroGrad()
+	}
+
+	out := f(leaves)
+	if out == nil {
+		return nil, ErrNilOutput
+	}
+	out.Backward()
+
+	var analytic = make([]float64, len(leaves))
+	for i, leaf := range leaves {
+		analytic[i] = leaf.Grad()
+	}
+
+	// Numeric pass.
+	var results = make([]Result, 0, len(leaves))
+	for i, leaf := range leaves {
+		original := leaf.Float64()
+
+		leaf.SetFloat64(original + epsilon)
+		plus := f(leaves)
+
+		leaf.SetFloat64(original - epsilon)
+		minus := f(leaves)
+
+		leaf.SetFloat64(original)
+
+		if plus == nil || minus == nil {
+			return nil, fmt.Errorf("leaf %d: %w", i, ErrNilOutput)
+		}
+
+		numeric := (plus.Float64() - minus.Float64()) / (2 * epsilon)
+
+		absoluteError := math.Abs(analytic[i] - numeric)
+		relativeError := relativeError(analytic[i], numeric)
+		allowedError := absTolerance + relTolerance*math.Max(math.Abs(analytic[i]), math.Abs(numeric))
+
+		results = append(results, Result{
+			Index:         i,
+			Label:         leaf.Label(),
+			Analytic:      analytic[i],
+			Numeric:       numeric,
+			AbsoluteError: absoluteError,
+			RelativeError: relativeError,
+			Passed:        absoluteError <= allowedError,
+		})
+	}
+
+	return &Report{
+		Results: results,
+	}, nil
+}
+
+func relativeError(a, b float64) float64 {
+	denominator := math.Max(math.Abs(a), math.Abs(b))
+	if denominator == 0 {
+		return 0
+	}
+
+	return math.Abs(a-b) / denominator
+}
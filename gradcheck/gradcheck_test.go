@@ -0,0 +1,209 @@
+package gradcheck
+
+import (
+	"grad2go/nn"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLeaves(xs ...float64) []*nn.Value {
+	var out = make([]*nn.Value, len(xs))
+	for i, x := range xs {
+		out[i] = nn.NewValue(decimal.NewFromFloat(x), nn.OperationNOOP, nn.KindInput, "")
+	}
+
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		leaves []float64
+		f      Func
+	}{
+		{
+			name:   "add",
+			leaves: []float64{1.5, -2.0},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Add(l[1]) },
+		},
+		{
+			name:   "sub",
+			leaves: []float64{1.5, -2.0},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Sub(l[1]) },
+		},
+		{
+			name:   "mul",
+			leaves: []float64{1.5, -2.0},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Mul(l[1]) },
+		},
+		{
+			name:   "div",
+			leaves: []float64{1.5, -2.0},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Div(l[1]) },
+		},
+		{
+			name:   "pow",
+			leaves: []float64{1.5},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Pow(decimal.NewFromInt(3)) },
+		},
+		{
+			name:   "relu",
+			leaves: []float64{0.7},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].ReLu() },
+		},
+		{
+			name:   "leaky_relu",
+			leaves: []float64{-0.7},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].LeakyReLu(0.01) },
+		},
+		{
+			name:   "tanh",
+			leaves: []float64{0.3},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Tanh() },
+		},
+		{
+			name:   "sigmoid",
+			leaves: []float64{-0.4},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Sigmoid() },
+		},
+		{
+			name:   "exp",
+			leaves: []float64{0.8},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Exp() },
+		},
+		{
+			name:   "log",
+			leaves: []float64{2.5},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Log() },
+		},
+		{
+			name:   "sqrt",
+			leaves: []float64{2.5},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Sqrt() },
+		},
+		{
+			name:   "neg",
+			leaves: []float64{2.5},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Neg() },
+		},
+		{
+			name:   "abs",
+			leaves: []float64{-2.5},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Abs() },
+		},
+		{
+			name:   "composite",
+			leaves: []float64{0.5, -1.5, 2.0},
+			f: func(l []*nn.Value) *nn.Value {
+				// tanh(a * b + c) / (a - c) ** 2.
+				num := l[0].Mul(l[1]).Add(l[2]).Tanh()
+				den := l[0].Sub(l[2]).Pow(decimal.NewFromInt(2))
+				return num.Div(den)
+			},
+		},
+		{
+			name:   "shared_leaf",
+			leaves: []float64{1.25},
+			f:      func(l []*nn.Value) *nn.Value { return l[0].Mul(l[0]).Sub(l[0]) },
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := Check(Config{}, tt.f, newLeaves(tt.leaves...))
+			require.NoError(t, err)
+			require.Len(t, report.Results, len(tt.leaves))
+
+			assert.True(t, report.Passed(), "failures: %v", report.Failures())
+		})
+	}
+}
+
+func TestCheckDetectsIncorrectGradient(t *testing.T) {
+	t.Parallel()
+
+	// The second leaf only reaches the output through a constant built from its data, so backpropagation gives it
+	// no gradient whereas its true gradient is 2 * 3.
+	leaves := newLeaves(2.0, 3.0)
+
+	report, err := Check(Config{}, func(l []*nn.Value) *nn.Value {
+		detached := nn.NewValue(decimal.NewFromFloat(l[1].Float64()*l[1].Float64()), nn.OperationNOOP, nn.KindValue, "")
+		return l[0].Mul(l[0]).Add(detached)
+	}, leaves)
+	require.NoError(t, err)
+
+	failures := report.Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, 1, failures[0].Index)
+	assert.InDelta(t, 0.0, failures[0].Analytic, 1e-9)
+	assert.InDelta(t, 6.0, failures[0].Numeric, 1e-6)
+}
+
+func TestCheckFlagsNonDifferentiablePoint(t *testing.T) {
+	t.Parallel()
+
+	// ReLu is not differentiable at zero; backpropagation takes the subgradient 0 whereas the central
+	// difference straddles the kink and estimates 0.5, so the check must flag the second leaf.
+	leaves := newLeaves(2.0, 0.0)
+
+	report, err := Check(Config{}, func(l []*nn.Value) *nn.Value { return l[0].Mul(l[0]).Add(l[1].ReLu()) }, leaves)
+	require.NoError(t, err)
+
+	failures := report.Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, 1, failures[0].Index)
+	assert.InDelta(t, 0.0, failures[0].Analytic, 1e-9)
+	assert.InDelta(t, 0.5, failures[0].Numeric, 1e-6)
+}
+
+func TestCheckDetectsSmallIncorrectGradient(t *testing.T) {
+	t.Parallel()
+
+	// The first leaf only reaches the output through a constant built from its data, so backpropagation gives it
+	// no gradient whereas its true gradient is 1e-6; both are tiny, but the analytic gradient is still wrong.
+	leaves := newLeaves(2.0, 3.0)
+
+	report, err := Check(Config{}, func(l []*nn.Value) *nn.Value {
+		detached := nn.NewValue(decimal.NewFromFloat(1e-6*l[0].Float64()), nn.OperationNOOP, nn.KindValue, "")
+		return detached.Add(l[1])
+	}, leaves)
+	require.NoError(t, err)
+
+	failures := report.Failures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, 0, failures[0].Index)
+	assert.InDelta(t, 0.0, failures[0].Analytic, 1e-12)
+	assert.InDelta(t, 1e-6, failures[0].Numeric, 1e-9)
+}
+
+func TestCheckResetsAccumulatedGradient(t *testing.T) {
+	t.Parallel()
+
+	leaves := newLeaves(3.0)
+	leaves[0].Mul(leaves[0]).Backward()
+
+	report, err := Check(Config{}, func(l []*nn.Value) *nn.Value { return l[0].Mul(l[0]) }, leaves)
+	require.NoError(t, err)
+
+	assert.True(t, report.Passed(), "failures: %v", report.Failures())
+	assert.InDelta(t, 6.0, report.Results[0].Analytic, 1e-9)
+}
+
+func TestCheckErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Check(Config{}, func(l []*nn.Value) *nn.Value { return nil }, nil)
+	assert.ErrorIs(t, err, ErrNoLeaves)
+
+	_, err = Check(Config{}, func(l []*nn.Value) *nn.Value { return nil }, newLeaves(1.0))
+	assert.ErrorIs(t, err, ErrNilOutput)
+}
@@ -0,0 +1,201 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+// BinaryCrossEntropyTerms is the criterion of -(y * log(p) + (1 - y) * log(1 - p)) for each output, each of which
+// is the probability of the positive class, e.g the output of a sigmoid. Targets must be within [0, 1]; hard targets
+// are attributed to class 0 or 1, so can be class weighted, whereas soft targets are attributed to NoClass.
+//
+// Probabilities are clamped to [1e-12, 1 - 1e-12]; a clamped probability is treated as a constant & so receives no
+// gradient. Prefer BinaryCrossEntropyWithLogitsTerms, which is stable without clamping.
+func BinaryCrossEntropyTerms(output, expectation []*nn.Value) ([]Term, error) {
+	targets, err := binaryTargets(output, expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms = make([]Term, len(output))
+	for i, p := range output {
+		y := targets[i]
+
+		switch f := p.Float64(); {
+		case f < probabilityEpsilon:
+			p = constant(p, probabilityEpsilon, "bce_clamp")
+		case f > 1-probabilityEpsilon:
+			p = constant(p, 1-probabilityEpsilon, "bce_clamp")
+		}
+
+		// Each side is left out of the graph when its target weight is zero.
+		var term *nn.Value
+		if y != 0 {
+			logP, err := p.TryLog()
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %w", i, err)
+			}
+
+			term = logP.Mul(constant(p, y, "target"))
+		}
+
+		if y != 1 {
+			logNotP, err := constant(p, 1, "one").Sub(p).TryLog()
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %w", i, err)
+			}
+
+			negative := logNotP.Mul(constant(p, 1-y, "target"))
+			if term == nil {
+				term = negative
+			} else {
+				term = term.Add(negative)
+			}
+		}
+
+		terms[i] = Term{Value: term.Neg(), Class: binaryClass(y)}
+	}
+
+	return terms, nil
+}
+
+// BinaryCrossEntropy is the mean of BinaryCrossEntropyTerms.
+func BinaryCrossEntropy(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(BinaryCrossEntropyTerms, Options{})(output, expectation)
+}
+
+// BinaryCrossEntropyWithLogitsTerms is BinaryCrossEntropyTerms applied to the sigmoid of the output logits. It is
+// computed as max(x, 0) - x * y + log(1 + exp(-|x|)), which never exponentiates a large positive number.
+func BinaryCrossEntropyWithLogitsTerms(output, expectation []*nn.Value) ([]Term, error) {
+	targets, err := binaryTargets(output, expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms = make([]Term, len(output))
+	for i, x := range output {
+		term, err := binaryCrossEntropyWithLogits(x, targets[i])
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+
+		terms[i] = Term{Value: term, Class: binaryClass(targets[i])}
+	}
+
+	return terms, nil
+}
+
+// BinaryCrossEntropyWithLogits is the mean of BinaryCrossEntropyWithLogitsTerms.
+func BinaryCrossEntropyWithLogits(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(BinaryCrossEntropyWithLogitsTerms, Options{})(output, expectation)
+}
+
+// FocalTerms returns the criterion of the focal loss of Lin et al. over output logits, which down weights well
+// classified examples so that training focuses on the hard ones:
+//
+//	loss = -alpha_t * (1 - p_t) ** gamma * log(p_t)
+//
+// where p_t is the predicted probability of the target class & alpha_t is alpha for positive targets & 1 - alpha for
+// negative ones. Alpha must be within [0, 1] & gamma must not be negative; a gamma of zero is an alpha weighted
+// BinaryCrossEntropyWithLogits. For a soft target y, 1 - p_t is taken as p ** (1 - y) * (1 - p) ** y.
+func FocalTerms(alpha, gamma float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		switch {
+		case !(alpha >= 0 && alpha <= 1):
+			return nil, fmt.Errorf("focal alpha %f must be within [0, 1]: %w", alpha, ErrInvalidParameter)
+		case !(gamma >= 0) || math.IsInf(gamma, 1):
+			return nil, fmt.Errorf("focal gamma %f must be finite & not negative: %w", gamma, ErrInvalidParameter)
+		}
+
+		targets, err := binaryTargets(output, expectation)
+		if err != nil {
+			return nil, err
+		}
+
+		var terms = make([]Term, len(output))
+		for i, x := range output {
+			y := targets[i]
+
+			// -log(p_t) is exactly the binary cross-entropy of the logit.
+			term, err := binaryCrossEntropyWithLogits(x, y)
+			if err != nil {
+				return nil, fmt.Errorf("output %d: %w", i, err)
+			}
+
+			if gamma != 0 {
+				// (1 - p_t) ** gamma is computed in log space as exp(-gamma * bce(x, 1 - y)): 1 - p_t saturates to
+				// exactly zero for a confident logit, where the gradient of a power below one is infinite.
+				complement, err := binaryCrossEntropyWithLogits(x, 1-y)
+				if err != nil {
+					return nil, fmt.Errorf("output %d: %w", i, err)
+				}
+
+				term = term.Mul(complement.Mul(constant(x, -gamma, "focal_gamma")).Exp())
+			}
+
+			alphaT := alpha*y + (1-alpha)*(1-y)
+			terms[i] = Term{Value: term.Mul(constant(x, alphaT, "focal_alpha")), Class: binaryClass(y)}
+		}
+
+		return terms, nil
+	}
+}
+
+// FocalLoss returns the mean of FocalTerms.
+func FocalLoss(alpha, gamma float64) nn.Losser {
+	return Reduce(FocalTerms(alpha, gamma), Options{})
+}
+
+// binaryClass attributes a hard binary target to its class.
+func binaryClass(y float64) int {
+	switch y {
+	case 0:
+		return 0
+	case 1:
+		return 1
+	default:
+		return NoClass
+	}
+}
+
+func binaryCrossEntropyWithLogits(x *nn.Value, y float64) (*nn.Value, error) {
+	// The branches are max(x, 0) - x * y + log(1 + exp(-|x|)) with the sign of x resolved; branching on the data,
+	// rather than composing ReLu & Abs, keeps the gradient exact at zero where both take a zero subgradient.
+	var linear, exponent *nn.Value
+	if x.Float64() >= 0 {
+		linear, exponent = x.Mul(constant(x, 1-y, "target")), x.Neg()
+	} else {
+		linear, exponent = x.Mul(constant(x, -y, "target")), x
+	}
+
+	softplus, err := constant(x, 1, "one").Add(exponent.Exp()).TryLog()
+	if err != nil {
+		return nil, err
+	}
+
+	return linear.Add(softplus), nil
+}
+
+// binaryTargets validates the shapes of a binary loss & returns the targets, which must be within [0, 1].
+func binaryTargets(output, expectation []*nn.Value) ([]float64, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	if len(output) != len(expectation) {
+		return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+	}
+
+	var targets = make([]float64, len(expectation))
+	for i, e := range expectation {
+		y := e.Float64()
+		if !(y >= 0 && y <= 1) {
+			return nil, fmt.Errorf("target %d of %f must be within [0, 1]: %w", i, y, ErrInvalidTarget)
+		}
+
+		targets[i] = y
+	}
+
+	return targets, nil
+}
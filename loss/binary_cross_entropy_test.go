@@ -0,0 +1,238 @@
+package loss
+
+import (
+	"grad2go/gradcheck"
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sigmoid(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+func bce(p, y float64) float64 { return -(y*math.Log(p) + (1-y)*math.Log(1-p)) }
+
+func TestBinaryCrossEntropy(t *testing.T) {
+	t.Parallel()
+
+	output := newTestValues(0.9, 0.2, 0.6)
+	loss, err := BinaryCrossEntropy(output, newTestValues(1, 0, 0.5))
+	require.NoError(t, err)
+
+	expected := (bce(0.9, 1) + bce(0.2, 0) + bce(0.6, 0.5)) / 3
+	assert.InDelta(t, expected, loss.Float64(), 1e-12)
+
+	// d(loss)/dp = (p - y) / (p * (1 - p)) / N.
+	loss.Backward()
+	assert.InDelta(t, (0.9-1)/(0.9*0.1)/3, output[0].Grad(), 1e-9)
+	assert.InDelta(t, (0.2-0)/(0.2*0.8)/3, output[1].Grad(), 1e-9)
+	assert.InDelta(t, (0.6-0.5)/(0.6*0.4)/3, output[2].Grad(), 1e-9)
+}
+
+func TestBinaryCrossEntropyClamp(t *testing.T) {
+	t.Parallel()
+
+	loss, err := BinaryCrossEntropy(newTestValues(0, 1), newTestValues(1, 0))
+	require.NoError(t, err)
+
+	assert.False(t, math.IsInf(loss.Float64(), 0))
+	assert.InDelta(t, -math.Log(probabilityEpsilon), loss.Float64(), 1e-3)
+}
+
+func TestBinaryCrossEntropyWithLogits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		logits []float64
+		target float64
+	}{
+		{name: "positive", logits: []float64{2.5}, target: 1},
+		{name: "negative", logits: []float64{-1.5}, target: 0},
+		{name: "soft", logits: []float64{0.3}, target: 0.25},
+		{name: "zero", logits: []float64{0}, target: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logits := newTestValues(tt.logits...)
+			loss, err := BinaryCrossEntropyWithLogits(logits, newTestValues(tt.target))
+			require.NoError(t, err)
+
+			p := sigmoid(tt.logits[0])
+			assert.InDelta(t, bce(p, tt.target), loss.Float64(), 1e-12)
+
+			// d(loss)/dx = sigmoid(x) - y.
+			loss.Backward()
+			assert.InDelta(t, p-tt.target, logits[0].Grad(), 1e-12)
+		})
+	}
+}
+
+func TestBinaryCrossEntropyWithLogitsLargeLogits(t *testing.T) {
+	t.Parallel()
+
+	loss, err := BinaryCrossEntropyWithLogits(newTestValues(1000, -1000), newTestValues(0, 1))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1000, loss.Float64(), 1e-9)
+}
+
+func TestFocalLoss(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		alpha float64
+		gamma float64
+	}{
+		{name: "cross_entropy", alpha: 0.5, gamma: 0},
+		{name: "focal", alpha: 0.25, gamma: 2},
+		{name: "fractional_gamma", alpha: 0.75, gamma: 1.5},
+	}
+
+	logits, targets := []float64{2, -0.5, 0.3}, []float64{1, 1, 0}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loss, err := FocalLoss(tt.alpha, tt.gamma)(newTestValues(logits...), newTestValues(targets...))
+			require.NoError(t, err)
+
+			var expected float64
+			for i, x := range logits {
+				p, y := sigmoid(x), targets[i]
+				pt := p*y + (1-p)*(1-y)
+				alphaT := tt.alpha*y + (1-tt.alpha)*(1-y)
+				expected += -alphaT * math.Pow(1-pt, tt.gamma) * math.Log(pt)
+			}
+			assert.InDelta(t, expected/3, loss.Float64(), 1e-12)
+
+			report, err := gradcheck.Check(gradcheck.Config{}, func(l []*nn.Value) *nn.Value {
+				out, err := FocalLoss(tt.alpha, tt.gamma)(l, newTestValues(targets...))
+				require.NoError(t, err)
+
+				return out
+			}, newTestValues(logits...))
+			require.NoError(t, err)
+			assert.True(t, report.Passed(), "failures: %v", report.Failures())
+		})
+	}
+}
+
+func TestFocalLossSaturatedLogit(t *testing.T) {
+	t.Parallel()
+
+	// With a gamma below one, the gradient of (1 - p_t) ** gamma is infinite once 1 - p_t saturates to zero.
+	for _, tt := range []struct {
+		name   string
+		logit  float64
+		target float64
+	}{
+		{name: "positive", logit: 40, target: 1},
+		{name: "negative", logit: -40, target: 0},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logits := newTestValues(tt.logit)
+			loss, err := FocalLoss(0.25, 0.5)(logits, newTestValues(tt.target))
+			require.NoError(t, err)
+
+			loss.Backward()
+
+			assert.False(t, math.IsNaN(loss.Float64()) || math.IsInf(loss.Float64(), 0))
+			assert.False(t, math.IsNaN(logits[0].Grad()) || math.IsInf(logits[0].Grad(), 0))
+			assert.InDelta(t, 0, logits[0].Grad(), 1e-6)
+		})
+	}
+}
+
+func TestBinaryLossErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "empty_output",
+			losser:      BinaryCrossEntropy,
+			expectedErr: ErrEmptyOutput,
+		},
+		{
+			name:        "shape_mismatch",
+			losser:      BinaryCrossEntropyWithLogits,
+			output:      []float64{0.5},
+			expectation: []float64{1, 0},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "target_out_of_range",
+			losser:      BinaryCrossEntropy,
+			output:      []float64{0.5},
+			expectation: []float64{2},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "nan_target",
+			losser:      BinaryCrossEntropy,
+			output:      []float64{0.5},
+			expectation: []float64{math.NaN()},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "negative_gamma",
+			losser:      FocalLoss(0.25, -1),
+			output:      []float64{0.5},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "nan_gamma",
+			losser:      FocalLoss(0.25, math.NaN()),
+			output:      []float64{0.5},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "nan_alpha",
+			losser:      FocalLoss(math.NaN(), 2),
+			output:      []float64{0.5},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "alpha_out_of_range",
+			losser:      FocalLoss(1.5, 2),
+			output:      []float64{0.5},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.losser(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+// SoftmaxCrossEntropyTerms is the criterion of the cross-entropy between the softmax of the output logits & the
+// target distribution, i.e -sum(t * log(softmax(output))), as a single term. The log softmax is computed directly
+// from the logits, so it stays finite for large logits; the output layer should therefore be linear rather than
+// softmax.
+//
+// The expectation is either a single class index, e.g [2], or a one-hot or soft distribution with the same shape
+// as the output. The term is attributed to the target class, unless the target is soft.
+func SoftmaxCrossEntropyTerms(output, expectation []*nn.Value) ([]Term, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	logProbabilities, err := nn.LogSoftmax(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return negativeLogLikelihoodTerms(logProbabilities, expectation)
+}
+
+// SoftmaxCrossEntropy is the reduction of SoftmaxCrossEntropyTerms.
+func SoftmaxCrossEntropy(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(SoftmaxCrossEntropyTerms, Options{})(output, expectation)
+}
+
+// NegativeLogLikelihoodTerms is the criterion of -sum(t * output) as a single term, where the output holds log
+// probabilities, e.g as computed by nn.LogSoftmax. The expectation is either a single class index or a
+// distribution, as for SoftmaxCrossEntropyTerms.
+func NegativeLogLikelihoodTerms(output, expectation []*nn.Value) ([]Term, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	return negativeLogLikelihoodTerms(output, expectation)
+}
+
+// NegativeLogLikelihood is the reduction of NegativeLogLikelihoodTerms.
+func NegativeLogLikelihood(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(NegativeLogLikelihoodTerms, Options{})(output, expectation)
+}
+
+func negativeLogLikelihoodTerms(logProbabilities, expectation []*nn.Value) ([]Term, error) {
+	targets, err := targetDistribution(len(logProbabilities), expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		summation *nn.Value
+		class     = NoClass
+		nonZero   int
+	)
+	for i, t := range targets {
+		// Classes with no target mass contribute nothing, so are left out of the graph.
+		if t == 0 {
+			continue
+		}
+		nonZero++
+
+		term := logProbabilities[i]
+		if t == 1 {
+			class = i
+		} else {
+			term = term.Mul(constant(logProbabilities[i], t, "target"))
+		}
+
+		if summation == nil {
+			summation = term
+			continue
+		}
+		summation = summation.Add(term)
+	}
+
+	if summation == nil {
+		return nil, fmt.Errorf("target distribution has no mass: %w", ErrInvalidTarget)
+	}
+
+	// Only a one-hot target is attributed to a class.
+	if nonZero != 1 {
+		class = NoClass
+	}
+
+	return []Term{{Value: summation.Neg(), Class: class}}, nil
+}
+
+// targetDistribution resolves the expectation into a distribution over the classes of the output. A single
+// expectation against multiple outputs is taken to be a class index, which is expanded to a one-hot distribution.
+func targetDistribution(classes int, expectation []*nn.Value) ([]float64, error) {
+	if len(expectation) == 1 && classes > 1 {
+		index := expectation[0].Float64()
+		if index != math.Trunc(index) || index < 0 || int(index) >= classes {
+			return nil, fmt.Errorf("class index %v must be an integer within [0, %d): %w", index, classes, ErrInvalidTarget)
+		}
+
+		var targets = make([]float64, classes)
+		targets[int(index)] = 1
+
+		return targets, nil
+	}
+
+	if len(expectation) != classes {
+		return nil, fmt.Errorf("expected shape %d, got %d: %w", classes, len(expectation), ErrInvalidShape)
+	}
+
+	var targets = make([]float64, classes)
+	for i, e := range expectation {
+		t := e.Float64()
+		if !(t >= 0) || math.IsInf(t, 1) {
+			return nil, fmt.Errorf("target %d of %f must be finite & not negative: %w", i, t, ErrInvalidTarget)
+		}
+
+		targets[i] = t
+	}
+
+	return targets, nil
+}
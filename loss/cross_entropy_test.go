@@ -0,0 +1,179 @@
+package loss
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValues(xs ...float64) []*nn.Value {
+	var out = make([]*nn.Value, len(xs))
+	for i, x := range xs {
+		out[i] = nn.NewValueFromScalar(nn.Float64Backend.FromFloat64(x), nn.OperationNOOP, nn.KindInput, "")
+	}
+
+	return out
+}
+
+func softmax(xs ...float64) []float64 {
+	var (
+		out = make([]float64, len(xs))
+		sum float64
+	)
+	for i, x := range xs {
+		out[i] = math.Exp(x)
+		sum += out[i]
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+
+	return out
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		logits      []float64
+		expectation []float64
+		// targets is the resolved target distribution.
+		targets []float64
+	}{
+		{
+			name:        "class_index",
+			logits:      []float64{1, 2, 0.5},
+			expectation: []float64{1},
+			targets:     []float64{0, 1, 0},
+		},
+		{
+			name:        "one_hot",
+			logits:      []float64{1, 2, 0.5},
+			expectation: []float64{0, 1, 0},
+			targets:     []float64{0, 1, 0},
+		},
+		{
+			name:        "soft",
+			logits:      []float64{1, 2, 0.5},
+			expectation: []float64{0.2, 0.5, 0.3},
+			targets:     []float64{0.2, 0.5, 0.3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logits := newTestValues(tt.logits...)
+			loss, err := SoftmaxCrossEntropy(logits, newTestValues(tt.expectation...))
+			require.NoError(t, err)
+
+			probabilities := softmax(tt.logits...)
+
+			var expected float64
+			for i, target := range tt.targets {
+				expected -= target * math.Log(probabilities[i])
+			}
+			assert.InDelta(t, expected, loss.Float64(), 1e-12)
+
+			// d(loss)/d(logit_i) = p_i - t_i, since the targets sum to one.
+			loss.Backward()
+			for i, l := range logits {
+				assert.InDelta(t, probabilities[i]-tt.targets[i], l.Grad(), 1e-9)
+			}
+		})
+	}
+}
+
+func TestSoftmaxCrossEntropyLargeLogits(t *testing.T) {
+	t.Parallel()
+
+	loss, err := SoftmaxCrossEntropy(newTestValues(-1000, 1000), newTestValues(0))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2000, loss.Float64(), 1e-9)
+}
+
+func TestNegativeLogLikelihood(t *testing.T) {
+	t.Parallel()
+
+	logProbabilities := newTestValues(math.Log(0.2), math.Log(0.8))
+
+	loss, err := NegativeLogLikelihood(logProbabilities, newTestValues(1))
+	require.NoError(t, err)
+	assert.InDelta(t, -math.Log(0.8), loss.Float64(), 1e-12)
+
+	loss.Backward()
+	assert.InDelta(t, 0, logProbabilities[0].Grad(), 1e-12)
+	assert.InDelta(t, -1, logProbabilities[1].Grad(), 1e-12)
+}
+
+func TestCrossEntropyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "empty_output",
+			expectation: []float64{0},
+			expectedErr: ErrEmptyOutput,
+		},
+		{
+			name:        "shape_mismatch",
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 1},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "class_index_out_of_range",
+			output:      []float64{1, 2},
+			expectation: []float64{2},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "fractional_class_index",
+			output:      []float64{1, 2},
+			expectation: []float64{0.5},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "negative_target",
+			output:      []float64{1, 2},
+			expectation: []float64{-0.5, 1.5},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "nan_target",
+			output:      []float64{1, 2},
+			expectation: []float64{math.NaN(), 1},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "no_mass",
+			output:      []float64{1, 2},
+			expectation: []float64{0, 0},
+			expectedErr: ErrInvalidTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := SoftmaxCrossEntropy(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
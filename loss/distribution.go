@@ -0,0 +1,332 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// KLDivergenceTerms returns the criterion of the Kullback-Leibler divergence KL(p || q) = sum(p * log(p / q)) as a
+// single term, where the output holds the predicted probabilities q & the expectation the target probabilities p.
+// Both distributions are normalized to sum to one. Predicted probabilities are clamped away from zero, as for
+// BinaryCrossEntropyTerms.
+//
+// A temperature other than one softens both distributions as softmax(log(x) / temperature) & scales the divergence
+// by temperature ** 2, so that gradients keep their magnitude; this is the soft target loss of knowledge
+// distillation. The temperature must be positive.
+func KLDivergenceTerms(temperature float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		targets, _, logQ, err := distributions(output, expectation, temperature)
+		if err != nil {
+			return nil, err
+		}
+
+		divergence := crossEntropyTo(targets, logQ).Neg().Sub(constant(logQ[0], entropy(targets), "target_entropy"))
+
+		return []Term{{Value: scaleByTemperature(divergence, temperature), Class: NoClass}}, nil
+	}
+}
+
+// KLDivergence returns the reduction of KLDivergenceTerms.
+func KLDivergence(temperature float64) nn.Losser {
+	return Reduce(KLDivergenceTerms(temperature), Options{})
+}
+
+// JensenShannonTerms returns the criterion of the Jensen-Shannon divergence between the predicted probabilities q of
+// the output & the target probabilities p of the expectation, as a single term:
+//
+//	0.5 * KL(p || m) + 0.5 * KL(q || m), where m = (p + q) / 2
+//
+// Unlike KL divergence it is symmetric & bounded by log(2). Both distributions are normalized to sum to one. The
+// temperature softens both distributions & scales the divergence as for KLDivergenceTerms; it must be positive.
+func JensenShannonTerms(temperature float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		targets, probabilities, logQ, err := distributions(output, expectation, temperature)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			half   = constant(logQ[0], 0.5, "half")
+			logM   = make([]*nn.Value, len(logQ))
+			qTerms []*nn.Value
+		)
+		for i, q := range probabilities {
+			m := q.Add(constant(q, targets[i], "target")).Mul(half)
+			if m.Float64() < probabilityEpsilon {
+				m = constant(q, probabilityEpsilon, "js_clamp")
+			}
+
+			logM[i], err = m.TryLog()
+			if err != nil {
+				return nil, fmt.Errorf("class %d: %w", i, err)
+			}
+
+			// q * (log(q) - log(m)); a zero probability contributes nothing.
+			if q.Float64() > 0 {
+				qTerms = append(qTerms, q.Mul(logQ[i].Sub(logM[i])))
+			}
+		}
+
+		// KL(p || m) = -H(p) - sum(p * log(m)).
+		divergence := crossEntropyTo(targets, logM).Neg().Sub(constant(half, entropy(targets), "target_entropy"))
+		if len(qTerms) > 0 {
+			divergence = divergence.Add(sum(qTerms))
+		}
+
+		return []Term{{Value: scaleByTemperature(divergence.Mul(half), temperature), Class: NoClass}}, nil
+	}
+}
+
+// JensenShannon returns the reduction of JensenShannonTerms.
+func JensenShannon(temperature float64) nn.Losser {
+	return Reduce(JensenShannonTerms(temperature), Options{})
+}
+
+// CosineEmbeddingTerms returns the labelled criterion of the cosine embedding loss between the output &
+// expectation embeddings as a single term, which pulls together similar pairs (a label of 1) & pushes apart
+// dissimilar pairs (a label of -1) until their cosine similarity falls below the margin:
+//
+//	1 - cos(x, y)             if label == 1
+//	max(0, cos(x, y) - margin) if label == -1
+//
+// The output & expectation must have the same shape; the label is passed separately, see LabelledCriterion.
+// Gradients flow into both embeddings. The margin must be within [-1, 1].
+func CosineEmbeddingTerms(margin float64) LabelledCriterion {
+	return func(output, expectation []*nn.Value, label float64) ([]Term, error) {
+		switch {
+		case !(margin >= -1 && margin <= 1):
+			return nil, fmt.Errorf("margin %f must be within [-1, 1]: %w", margin, ErrInvalidParameter)
+		case label != 1 && label != -1:
+			return nil, fmt.Errorf("label %f must be -1 or 1: %w", label, ErrInvalidTarget)
+		case len(output) == 0:
+			return nil, ErrEmptyOutput
+		case len(output) != len(expectation):
+			return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+		}
+
+		var (
+			dot             = make([]*nn.Value, len(output))
+			outputNorm      = make([]*nn.Value, len(output))
+			expectationNorm = make([]*nn.Value, len(output))
+		)
+		for i := range output {
+			dot[i] = output[i].Mul(expectation[i])
+			outputNorm[i] = output[i].Pow(decimal.NewFromInt(2))
+			expectationNorm[i] = expectation[i].Pow(decimal.NewFromInt(2))
+		}
+
+		outputLength, err := sum(outputNorm).TrySqrt()
+		if err != nil {
+			return nil, err
+		}
+
+		expectationLength, err := sum(expectationNorm).TrySqrt()
+		if err != nil {
+			return nil, err
+		}
+
+		cosine, err := sum(dot).TryDiv(outputLength.Mul(expectationLength))
+		if err != nil {
+			return nil, fmt.Errorf("cosine similarity of a zero embedding: %w", err)
+		}
+
+		if label == 1 {
+			return []Term{{Value: constant(cosine, 1, "one").Sub(cosine), Class: NoClass}}, nil
+		}
+
+		return []Term{{Value: cosine.Sub(constant(cosine, margin, "margin")).ReLu(), Class: NoClass}}, nil
+	}
+}
+
+// CosineEmbedding returns the reduction of CosineEmbeddingTerms, reading the label of each pair from its sample.
+func CosineEmbedding(margin float64) nn.SampleLosser {
+	return ReduceLabelled(CosineEmbeddingTerms(margin), Options{})
+}
+
+// distributions validates a distribution loss & returns its target probabilities together with its predicted
+// probabilities & their log, all normalized & softened by the temperature.
+func distributions(
+	output, expectation []*nn.Value,
+	temperature float64,
+) ([]float64, []*nn.Value, []*nn.Value, error) {
+	if !(temperature > 0) || math.IsInf(temperature, 1) {
+		return nil, nil, nil, fmt.Errorf(
+			"temperature %f must be finite & positive: %w", temperature, ErrInvalidParameter,
+		)
+	}
+
+	targets, err := distributionTargets(output, expectation)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	q, err := normalize(output)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logQ, err := logProbabilities(q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if temperature == 1 {
+		return targets, q, logQ, nil
+	}
+
+	for i, l := range logQ {
+		logQ[i] = l.Mul(constant(l, 1/temperature, "temperature"))
+	}
+
+	logQ, err = nn.LogSoftmax(logQ)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i, l := range logQ {
+		q[i] = l.Exp()
+	}
+
+	return soften(targets, temperature), q, logQ, nil
+}
+
+// scaleByTemperature scales a divergence between softened distributions by temperature ** 2.
+func scaleByTemperature(divergence *nn.Value, temperature float64) *nn.Value {
+	if temperature == 1 {
+		return divergence
+	}
+
+	return divergence.Mul(constant(divergence, temperature*temperature, "temperature_squared"))
+}
+
+// distributionTargets validates the shapes of a distribution loss & returns the target probabilities, which must
+// be finite, not negative & must have some mass; they are normalized to sum to one.
+func distributionTargets(output, expectation []*nn.Value) ([]float64, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	if len(output) != len(expectation) {
+		return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+	}
+
+	var (
+		targets = make([]float64, len(expectation))
+		mass    float64
+	)
+	for i, e := range expectation {
+		p := e.Float64()
+		if !(p >= 0) || math.IsInf(p, 1) {
+			return nil, fmt.Errorf("target %d of %f must be finite & not negative: %w", i, p, ErrInvalidTarget)
+		}
+
+		targets[i] = p
+		mass += p
+	}
+
+	if mass == 0 {
+		return nil, fmt.Errorf("target distribution has no mass: %w", ErrInvalidTarget)
+	}
+
+	for i := range targets {
+		targets[i] /= mass
+	}
+
+	return targets, nil
+}
+
+// normalize divides the predicted probabilities by their sum within the graph, so they sum to one.
+func normalize(probabilities []*nn.Value) ([]*nn.Value, error) {
+	mass := sum(probabilities)
+	if mass.Float64() <= 0 {
+		return nil, fmt.Errorf("predicted distribution has no mass: %w", nn.ErrOutOfDomain)
+	}
+
+	var out = make([]*nn.Value, len(probabilities))
+	for i, p := range probabilities {
+		normalized, err := p.TryDiv(mass)
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = normalized
+	}
+
+	return out, nil
+}
+
+// logProbabilities returns the log of each probability, clamping probabilities below probabilityEpsilon.
+func logProbabilities(probabilities []*nn.Value) ([]*nn.Value, error) {
+	var out = make([]*nn.Value, len(probabilities))
+	for i, p := range probabilities {
+		if p.Float64() < probabilityEpsilon {
+			p = constant(p, probabilityEpsilon, "probability_clamp")
+		}
+
+		logP, err := p.TryLog()
+		if err != nil {
+			return nil, fmt.Errorf("class %d: %w", i, err)
+		}
+
+		out[i] = logP
+	}
+
+	return out, nil
+}
+
+// crossEntropyTo returns sum(p * log(q)) over the classes with target mass.
+func crossEntropyTo(targets []float64, logQ []*nn.Value) *nn.Value {
+	var terms []*nn.Value
+	for i, p := range targets {
+		if p == 0 {
+			continue
+		}
+
+		terms = append(terms, logQ[i].Mul(constant(logQ[i], p, "target")))
+	}
+
+	return sum(terms)
+}
+
+// entropy returns -sum(p * log(p)) of the targets, taking 0 * log(0) to be zero.
+func entropy(targets []float64) float64 {
+	var h float64
+	for _, p := range targets {
+		if p > 0 {
+			h -= p * math.Log(p)
+		}
+	}
+
+	return h
+}
+
+// soften returns softmax(log(p) / temperature) of the target probabilities p.
+func soften(targets []float64, temperature float64) []float64 {
+	var (
+		logits   = make([]float64, len(targets))
+		maxLogit = math.Inf(-1)
+	)
+	for i, p := range targets {
+		// The log of a zero probability is -Inf, which exponentiates back to zero below.
+		logits[i] = math.Log(p) / temperature
+		maxLogit = math.Max(maxLogit, logits[i])
+	}
+
+	var (
+		out   = make([]float64, len(targets))
+		total float64
+	)
+	for i, l := range logits {
+		out[i] = math.Exp(l - maxLogit)
+		total += out[i]
+	}
+
+	for i := range out {
+		out[i] /= total
+	}
+
+	return out
+}
@@ -0,0 +1,384 @@
+package loss
+
+import (
+	"grad2go/gradcheck"
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func kl(p, q []float64) float64 {
+	var out float64
+	for i := range p {
+		if p[i] > 0 {
+			out += p[i] * math.Log(p[i]/q[i])
+		}
+	}
+
+	return out
+}
+
+func jensenShannon(p, q []float64) float64 {
+	var m = make([]float64, len(p))
+	for i := range p {
+		m[i] = (p[i] + q[i]) / 2
+	}
+
+	return 0.5*kl(p, m) + 0.5*kl(q, m)
+}
+
+// labelled returns a losser which passes the label to the sample losser as the sample of every call.
+func labelled(losser nn.SampleLosser, label float64) nn.Losser {
+	return func(output, expectation []*nn.Value) (*nn.Value, error) {
+		return losser(output, expectation, nn.Sample{Weight: 1, Label: label})
+	}
+}
+
+func TestDistributionLosses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		expected    float64
+	}{
+		{
+			name:        "kl_divergence",
+			losser:      KLDivergence(1),
+			output:      []float64{0.25, 0.75},
+			expectation: []float64{0.5, 0.5},
+			expected:    kl([]float64{0.5, 0.5}, []float64{0.25, 0.75}),
+		},
+		{
+			name:        "kl_divergence_temperature",
+			losser:      KLDivergence(2),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+			expected: 4 * kl(
+				soften([]float64{0.6, 0.3, 0.1}, 2),
+				soften([]float64{0.1, 0.2, 0.7}, 2),
+			),
+		},
+		{
+			name:        "kl_divergence_zero_target",
+			losser:      KLDivergence(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{1, 0},
+			expected:    math.Ln2,
+		},
+		{
+			name:        "kl_divergence_identical",
+			losser:      KLDivergence(1),
+			output:      []float64{0.3, 0.7},
+			expectation: []float64{0.3, 0.7},
+			expected:    0,
+		},
+		{
+			name:        "kl_divergence_normalized",
+			losser:      KLDivergence(1),
+			output:      []float64{1, 3},
+			expectation: []float64{2, 2},
+			expected:    kl([]float64{0.5, 0.5}, []float64{0.25, 0.75}),
+		},
+		{
+			// Count-like targets are normalized to a distribution.
+			name:        "kl_divergence_counts",
+			losser:      KLDivergence(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{2, 2},
+			expected:    0,
+		},
+		{
+			name:        "kl_divergence_counts_temperature",
+			losser:      KLDivergence(1.0001),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{2, 2},
+			expected:    0,
+		},
+		{
+			name:        "jensen_shannon_normalized",
+			losser:      JensenShannon(1),
+			output:      []float64{0.6, 1.4},
+			expectation: []float64{1.2, 0.8},
+			expected:    0.5*kl([]float64{0.6, 0.4}, []float64{0.45, 0.55}) + 0.5*kl([]float64{0.3, 0.7}, []float64{0.45, 0.55}),
+		},
+		{
+			name:        "jensen_shannon",
+			losser:      JensenShannon(1),
+			output:      []float64{0.3, 0.7},
+			expectation: []float64{0.6, 0.4},
+			expected:    0.5*kl([]float64{0.6, 0.4}, []float64{0.45, 0.55}) + 0.5*kl([]float64{0.3, 0.7}, []float64{0.45, 0.55}),
+		},
+		{
+			name:        "jensen_shannon_temperature",
+			losser:      JensenShannon(2),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+			expected:    4 * jensenShannon(soften([]float64{0.6, 0.3, 0.1}, 2), soften([]float64{0.1, 0.2, 0.7}, 2)),
+		},
+		{
+			name:        "jensen_shannon_disjoint",
+			losser:      JensenShannon(1),
+			output:      []float64{0, 1},
+			expectation: []float64{1, 0},
+			expected:    math.Ln2,
+		},
+		{
+			name:        "cosine_embedding_similar",
+			losser:      labelled(CosineEmbedding(0), 1),
+			output:      []float64{1, 0},
+			expectation: []float64{0, 1},
+			expected:    1,
+		},
+		{
+			name:        "cosine_embedding_dissimilar",
+			losser:      labelled(CosineEmbedding(0.5), -1),
+			output:      []float64{1, 1},
+			expectation: []float64{1, 0},
+			expected:    1/math.Sqrt2 - 0.5,
+		},
+		{
+			name:        "cosine_embedding_dissimilar_within_margin",
+			losser:      labelled(CosineEmbedding(0.5), -1),
+			output:      []float64{1, 0},
+			expectation: []float64{0, 1},
+			expected:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loss, err := tt.losser(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			require.NoError(t, err)
+
+			assert.InDelta(t, tt.expected, loss.Float64(), 1e-12)
+		})
+	}
+}
+
+func TestCosineEmbeddingMixedPairs(t *testing.T) {
+	t.Parallel()
+
+	// A single losser trains on both similar & dissimilar pairs, reading the label from the sample of each call.
+	losser := CosineEmbedding(0.5)
+
+	similar, err := losser(newTestValues(1, 1), newTestValues(1, 0), nn.Sample{Weight: 1, Label: 1})
+	require.NoError(t, err)
+	assert.InDelta(t, 1-1/math.Sqrt2, similar.Float64(), 1e-12)
+
+	dissimilar, err := losser(newTestValues(1, 1), newTestValues(1, 0), nn.Sample{Weight: 1, Label: -1})
+	require.NoError(t, err)
+	assert.InDelta(t, 1/math.Sqrt2-0.5, dissimilar.Float64(), 1e-12)
+
+	_, err = losser(newTestValues(1, 1), newTestValues(1, 0), nn.Sample{Weight: 1})
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestDistributionLossGradients(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		// embedding checks the gradient w.r.t the expectation as well as the output.
+		embedding bool
+	}{
+		{
+			name:        "kl_divergence",
+			losser:      KLDivergence(1),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+		},
+		{
+			name:        "kl_divergence_temperature",
+			losser:      KLDivergence(3),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+		},
+		{
+			name:        "jensen_shannon",
+			losser:      JensenShannon(1),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+		},
+		{
+			name:        "jensen_shannon_temperature",
+			losser:      JensenShannon(0.5),
+			output:      []float64{0.1, 0.2, 0.7},
+			expectation: []float64{0.6, 0.3, 0.1},
+		},
+		{
+			name:        "kl_divergence_unnormalized",
+			losser:      KLDivergence(1),
+			output:      []float64{0.2, 0.4, 1.4},
+			expectation: []float64{6, 3, 1},
+		},
+		{
+			name:        "cosine_embedding_similar",
+			losser:      labelled(CosineEmbedding(0), 1),
+			output:      []float64{0.5, -1, 2},
+			expectation: []float64{1, 0.5, 0.25},
+			embedding:   true,
+		},
+		{
+			name:        "cosine_embedding_dissimilar",
+			losser:      labelled(CosineEmbedding(-0.5), -1),
+			output:      []float64{0.5, -1, 2},
+			expectation: []float64{1, 0.5, 0.25},
+			embedding:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var size = len(tt.output)
+			leaves := newTestValues(tt.output...)
+			if tt.embedding {
+				leaves = append(leaves, newTestValues(tt.expectation...)...)
+			}
+
+			report, err := gradcheck.Check(gradcheck.Config{}, func(l []*nn.Value) *nn.Value {
+				expectation := newTestValues(tt.expectation...)
+				if tt.embedding {
+					expectation = l[size:]
+				}
+
+				out, err := tt.losser(l[:size], expectation)
+				require.NoError(t, err)
+
+				return out
+			}, leaves)
+			require.NoError(t, err)
+
+			assert.True(t, report.Passed(), "failures: %v", report.Failures())
+		})
+	}
+}
+
+func TestDistributionLossErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "empty_output",
+			losser:      JensenShannon(1),
+			expectedErr: ErrEmptyOutput,
+		},
+		{
+			name:        "shape_mismatch",
+			losser:      KLDivergence(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "temperature",
+			losser:      KLDivergence(0),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{0.5, 0.5},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "negative_target",
+			losser:      KLDivergence(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{1.5, -0.5},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "nan_target",
+			losser:      KLDivergence(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{math.NaN(), 0.5},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "infinite_target",
+			losser:      JensenShannon(1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{math.Inf(1), 0.5},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "jensen_shannon_temperature",
+			losser:      JensenShannon(-1),
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{0.5, 0.5},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "output_without_mass",
+			losser:      KLDivergence(1),
+			output:      []float64{0, 0},
+			expectation: []float64{0.5, 0.5},
+			expectedErr: nn.ErrOutOfDomain,
+		},
+		{
+			name:        "cosine_embedding_label",
+			losser:      labelled(CosineEmbedding(0), 0),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "cosine_embedding_margin",
+			losser:      labelled(CosineEmbedding(2), -1),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "cosine_embedding_nan_margin",
+			losser:      labelled(CosineEmbedding(math.NaN()), 1),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "cosine_embedding_shape",
+			losser:      labelled(CosineEmbedding(0), 1),
+			output:      []float64{1, 0},
+			expectation: []float64{1, 0, 1},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "cosine_embedding_zero",
+			losser:      labelled(CosineEmbedding(0), 1),
+			output:      []float64{0, 0},
+			expectation: []float64{1, 0},
+			expectedErr: nn.ErrDivisionByZero,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.losser(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+
+	"github.com/shopspring/decimal"
+)
+
+// HingeTerms is the criterion of the hinge loss max(0, 1 - y * f) of each output f, where the targets y are -1 or
+// 1. Positive targets are attributed to class 1 & negative targets to class 0.
+func HingeTerms(output, expectation []*nn.Value) ([]Term, error) {
+	return marginTerms(output, expectation, func(margin *nn.Value) *nn.Value {
+		return margin
+	})
+}
+
+// Hinge is the mean of HingeTerms; with a linear output layer it trains a linear SVM.
+func Hinge(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(HingeTerms, Options{})(output, expectation)
+}
+
+// SquaredHingeTerms is the criterion of max(0, 1 - y * f) ** 2 of each output f, which is differentiable at the
+// margin; targets are as for HingeTerms.
+func SquaredHingeTerms(output, expectation []*nn.Value) ([]Term, error) {
+	return marginTerms(output, expectation, func(margin *nn.Value) *nn.Value {
+		return margin.Pow(decimal.NewFromInt(2))
+	})
+}
+
+// SquaredHinge is the mean of SquaredHingeTerms.
+func SquaredHinge(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(SquaredHingeTerms, Options{})(output, expectation)
+}
+
+// MultiClassHingeTerms is the criterion of the multi-class hinge loss of Crammer & Singer as a single term:
+//
+//	max(0, 1 + max_{j != y}(f_j) - f_y)
+//
+// which requires the score of the target class to exceed every other score by a margin of one. The expectation is a
+// single class index or a one-hot distribution, as for SoftmaxCrossEntropyTerms, & the term is attributed to the
+// target class.
+func MultiClassHingeTerms(output, expectation []*nn.Value) ([]Term, error) {
+	if len(output) < 2 {
+		return nil, fmt.Errorf("multi-class hinge requires at least 2 classes, got %d: %w", len(output), ErrInvalidShape)
+	}
+
+	targets, err := targetDistribution(len(output), expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var class = NoClass
+	for i, t := range targets {
+		switch {
+		case t == 1 && class == NoClass:
+			class = i
+		case t != 0:
+			return nil, fmt.Errorf("multi-class hinge requires a one-hot target: %w", ErrInvalidTarget)
+		}
+	}
+
+	if class == NoClass {
+		return nil, fmt.Errorf("multi-class hinge requires a one-hot target: %w", ErrInvalidTarget)
+	}
+
+	// Only the highest scoring rival class takes part in the loss, so only it receives a gradient.
+	var rival = NoClass
+	for j, f := range output {
+		if j == class {
+			continue
+		}
+
+		if rival == NoClass || f.Float64() > output[rival].Float64() {
+			rival = j
+		}
+	}
+
+	margin := constant(output[class], 1, "margin").Add(output[rival]).Sub(output[class]).ReLu()
+
+	return []Term{{Value: margin, Class: class}}, nil
+}
+
+// MultiClassHinge is the reduction of MultiClassHingeTerms.
+func MultiClassHinge(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(MultiClassHingeTerms, Options{})(output, expectation)
+}
+
+// marginTerms validates the ±1 targets of a binary margin loss & applies penalty to max(0, 1 - y * f).
+func marginTerms(output, expectation []*nn.Value, penalty func(margin *nn.Value) *nn.Value) ([]Term, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	if len(output) != len(expectation) {
+		return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+	}
+
+	var terms = make([]Term, len(output))
+	for i, f := range output {
+		y := expectation[i].Float64()
+
+		var class int
+		switch y {
+		case 1:
+			class = 1
+		case -1:
+			class = 0
+		default:
+			return nil, fmt.Errorf("target %d of %f must be -1 or 1: %w", i, y, ErrInvalidTarget)
+		}
+
+		margin := constant(f, 1, "margin").Sub(f.Mul(constant(f, y, "target"))).ReLu()
+		terms[i] = Term{Value: penalty(margin), Class: class}
+	}
+
+	return terms, nil
+}
@@ -0,0 +1,131 @@
+package loss
+
+import (
+	"grad2go/nn"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHingeLosses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		losser        nn.Losser
+		output        []float64
+		expectation   []float64
+		expected      float64
+		expectedGrads []float64
+	}{
+		{
+			name:          "hinge",
+			losser:        Hinge,
+			output:        []float64{2, 0.5, -0.3},
+			expectation:   []float64{1, 1, -1},
+			expected:      (0 + 0.5 + 0.7) / 3,
+			expectedGrads: []float64{0, -1.0 / 3, 1.0 / 3},
+		},
+		{
+			name:          "squared_hinge",
+			losser:        SquaredHinge,
+			output:        []float64{2, 0.5, -0.3},
+			expectation:   []float64{1, 1, -1},
+			expected:      (0 + 0.25 + 0.49) / 3,
+			expectedGrads: []float64{0, -1.0 / 3, 1.4 / 3},
+		},
+		{
+			name:          "multi_class_hinge",
+			losser:        MultiClassHinge,
+			output:        []float64{1, 2, 0.5},
+			expectation:   []float64{0},
+			expected:      2,
+			expectedGrads: []float64{-1, 1, 0},
+		},
+		{
+			name:          "multi_class_hinge_one_hot",
+			losser:        MultiClassHinge,
+			output:        []float64{1, 2, 0.5},
+			expectation:   []float64{0, 0, 1},
+			expected:      2.5,
+			expectedGrads: []float64{0, 1, -1},
+		},
+		{
+			name:          "multi_class_hinge_satisfied",
+			losser:        MultiClassHinge,
+			output:        []float64{3, 1, 0.5},
+			expectation:   []float64{0},
+			expected:      0,
+			expectedGrads: []float64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := newTestValues(tt.output...)
+			loss, err := tt.losser(output, newTestValues(tt.expectation...))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, loss.Float64(), 1e-12)
+
+			loss.Backward()
+			for i, o := range output {
+				assert.InDelta(t, tt.expectedGrads[i], o.Grad(), 1e-12, "output %d", i)
+			}
+		})
+	}
+}
+
+func TestHingeLossErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "empty_output",
+			losser:      Hinge,
+			expectedErr: ErrEmptyOutput,
+		},
+		{
+			name:        "zero_one_target",
+			losser:      SquaredHinge,
+			output:      []float64{0.5},
+			expectation: []float64{0},
+			expectedErr: ErrInvalidTarget,
+		},
+		{
+			name:        "single_class",
+			losser:      MultiClassHinge,
+			output:      []float64{0.5},
+			expectation: []float64{0},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "soft_target",
+			losser:      MultiClassHinge,
+			output:      []float64{0.5, 0.5},
+			expectation: []float64{0.5, 0.5},
+			expectedErr: ErrInvalidTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.losser(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -1 +1,42 @@
 package loss
+
+import (
+	"errors"
+	"grad2go/nn"
+)
+
+// probabilityEpsilon bounds probabilities away from zero & one, so that their log stays finite.
+const probabilityEpsilon = 1e-12
+
+var (
+	ErrInvalidShape     = errors.New("invalid shape")
+	ErrEmptyOutput      = errors.New("empty output")
+	ErrInvalidParameter = errors.New("invalid parameter")
+	ErrInvalidTarget    = errors.New("invalid target")
+	ErrInvalidReduction = errors.New("invalid reduction")
+)
+
+// sum returns the sum of the terms, which must not be empty.
+func sum(terms []*nn.Value) *nn.Value {
+	summation := terms[0]
+	for _, term := range terms[1:] {
+		summation = summation.Add(term)
+	}
+
+	return summation
+}
+
+// mean returns the mean of the terms, which must not be empty.
+func mean(terms []*nn.Value) (*nn.Value, error) {
+	summation := sum(terms)
+	if len(terms) == 1 {
+		return summation, nil
+	}
+
+	return summation.TryDiv(constant(summation, float64(len(terms)), "mean_divisor"))
+}
+
+// constant returns a leaf holding f on the backend of like, for use as a fixed coefficient in the graph.
+func constant(like *nn.Value, f float64, label string) *nn.Value {
+	return nn.NewValueFromScalar(like.Backend().FromFloat64(f), nn.OperationNOOP, nn.KindValue, label)
+}
@@ -0,0 +1,163 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+// NoClass is the class of a term which isn't attributed to any class, so is never class weighted.
+const NoClass = -1
+
+// Reduction is how Reduce combines the weighted terms into a single loss; Unreduced keeps the terms instead.
+type Reduction int
+
+const (
+	// ReductionMean averages the weighted terms over the number of terms.
+	ReductionMean Reduction = iota
+	// ReductionSum sums the weighted terms.
+	ReductionSum
+)
+
+func (r Reduction) String() string {
+	switch r {
+	case ReductionMean:
+		return "mean"
+	case ReductionSum:
+		return "sum"
+	default:
+		return "unknown"
+	}
+}
+
+type Options struct {
+	// Reduction defaults to ReductionMean.
+	Reduction Reduction
+	// ElementWeights scales each term by the weight at its index; if set it must have one weight per term. The
+	// weights are fixed per element of the output, e.g to down weight a noisy output, & are reused by every step;
+	// each sample is weighted by nn.NeuralNetwork.StepSample instead. Every weight must be finite & not negative.
+	ElementWeights []float64
+	// ClassWeights scales each term by the weight of the class it is attributed to, e.g to up weight a rare class.
+	// Terms attributed to NoClass are not scaled. Unlike the usual weighted mean, ReductionMean still divides by
+	// the number of terms rather than by the sum of their weights, so class weights also scale the loss.
+	ClassWeights []float64
+}
+
+// Term is the loss of a single element of the output, before any weighting or reduction.
+type Term struct {
+	Value *nn.Value
+	// Class is the class the term is attributed to, which selects its class weight; NoClass if there is none.
+	Class int
+}
+
+// Criterion computes the unreduced terms of a loss.
+type Criterion func(output, expectation []*nn.Value) ([]Term, error)
+
+// LabelledCriterion computes the unreduced terms of a loss over a pair which also has a label, e.g whether the pair
+// is similar. The label is an argument of its own rather than an element of the expectation, so the expectation
+// keeps the shape of the output.
+type LabelledCriterion func(output, expectation []*nn.Value, label float64) ([]Term, error)
+
+// WithLabel returns the criterion of pairs with the given label, e.g to evaluate pairs which all share a label.
+func (c LabelledCriterion) WithLabel(label float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		return c(output, expectation, label)
+	}
+}
+
+// Reduce builds a losser which weights the terms of the criterion & reduces them to a single value. The mean
+// divides by the number of terms rather than by the total weight, so that weights keep their effect when each
+// step sees a single sample.
+func Reduce(criterion Criterion, opts Options) nn.Losser {
+	return func(output, expectation []*nn.Value) (*nn.Value, error) {
+		values, err := Unreduced(criterion, opts)(output, expectation)
+		if err != nil {
+			return nil, err
+		}
+
+		switch opts.Reduction {
+		case ReductionMean:
+			return mean(values)
+		case ReductionSum:
+			return sum(values), nil
+		default:
+			return nil, fmt.Errorf("unknown reduction %d: %w", opts.Reduction, ErrInvalidReduction)
+		}
+	}
+}
+
+// ReduceLabelled builds a sample losser which reduces the terms of the criterion for the label of each sample, as
+// Reduce does; a single losser so trains on pairs of every label through nn.NeuralNetwork.StepSample.
+func ReduceLabelled(criterion LabelledCriterion, opts Options) nn.SampleLosser {
+	return func(output, expectation []*nn.Value, sample nn.Sample) (*nn.Value, error) {
+		return Reduce(criterion.WithLabel(sample.Label), opts)(output, expectation)
+	}
+}
+
+// Unreduced builds a function returning the weighted terms of the criterion without reducing them, i.e the "none"
+// reduction, e.g to inspect the loss of each element; it only applies the weights of the options, as there is no
+// reduction to apply.
+func Unreduced(criterion Criterion, opts Options) func(output, expectation []*nn.Value) ([]*nn.Value, error) {
+	return func(output, expectation []*nn.Value) ([]*nn.Value, error) {
+		terms, err := criterion(output, expectation)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(terms) == 0 {
+			return nil, ErrEmptyOutput
+		}
+
+		if opts.ElementWeights != nil && len(opts.ElementWeights) != len(terms) {
+			return nil, fmt.Errorf(
+				"expected %d element weights, got %d: %w", len(terms), len(opts.ElementWeights), ErrInvalidShape,
+			)
+		}
+
+		var values = make([]*nn.Value, len(terms))
+		for i, term := range terms {
+			weight, err := termWeight(i, term, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = term.Value
+			if weight != 1 {
+				values[i] = term.Value.Mul(constant(term.Value, weight, "loss_weight"))
+			}
+		}
+
+		return values, nil
+	}
+}
+
+func termWeight(index int, term Term, opts Options) (float64, error) {
+	var weight = 1.0
+
+	if opts.ElementWeights != nil {
+		weight *= opts.ElementWeights[index]
+	}
+
+	if opts.ClassWeights != nil && term.Class != NoClass {
+		if term.Class < 0 || term.Class >= len(opts.ClassWeights) {
+			return 0, fmt.Errorf(
+				"term %d has class %d without a class weight: %w", index, term.Class, ErrInvalidShape,
+			)
+		}
+
+		weight *= opts.ClassWeights[term.Class]
+	}
+
+	if !validWeight(weight) {
+		return 0, fmt.Errorf(
+			"term %d has weight %f, which must be finite & not negative: %w", index, weight, ErrInvalidParameter,
+		)
+	}
+
+	return weight, nil
+}
+
+// validWeight reports whether the weight is finite & not negative.
+func validWeight(weight float64) bool {
+	return weight >= 0 && !math.IsInf(weight, 1)
+}
@@ -0,0 +1,208 @@
+package loss
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		criterion   Criterion
+		opts        Options
+		output      []float64
+		expectation []float64
+		expected    float64
+	}{
+		{
+			name:        "mean",
+			criterion:   SquaredErrorTerms,
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 0, 0},
+			expected:    14.0 / 3,
+		},
+		{
+			name:        "sum",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{Reduction: ReductionSum},
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 0, 0},
+			expected:    14,
+		},
+		{
+			name:        "element_weights_mean",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ElementWeights: []float64{1, 0, 2}},
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 0, 0},
+			expected:    19.0 / 3,
+		},
+		{
+			name:        "element_weights_sum",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{Reduction: ReductionSum, ElementWeights: []float64{1, 0, 2}},
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 0, 0},
+			expected:    19,
+		},
+		{
+			name:        "weighted_mean_divides_by_term_count",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ElementWeights: []float64{2, 2, 2}},
+			output:      []float64{1, 1, 1},
+			expectation: []float64{0, 0, 0},
+			// Dividing by the total weight would give 1.
+			expected: 2,
+		},
+		{
+			name:        "regression_ignores_class_weights",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ClassWeights: []float64{5}},
+			output:      []float64{1, 2, 3},
+			expectation: []float64{0, 0, 0},
+			expected:    14.0 / 3,
+		},
+		{
+			name:        "binary_class_weights",
+			criterion:   BinaryCrossEntropyWithLogitsTerms,
+			opts:        Options{ClassWeights: []float64{1, 3}},
+			output:      []float64{0, 0},
+			expectation: []float64{1, 0},
+			expected:    (3*math.Ln2 + math.Ln2) / 2,
+		},
+		{
+			name:        "class_index_class_weights",
+			criterion:   SoftmaxCrossEntropyTerms,
+			opts:        Options{ClassWeights: []float64{1, 2}},
+			output:      []float64{0, 0},
+			expectation: []float64{1},
+			expected:    2 * math.Ln2,
+		},
+		{
+			name:        "soft_target_ignores_class_weights",
+			criterion:   SoftmaxCrossEntropyTerms,
+			opts:        Options{ClassWeights: []float64{1, 2}},
+			output:      []float64{0, 0},
+			expectation: []float64{0.5, 0.5},
+			expected:    math.Ln2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loss, err := Reduce(tt.criterion, tt.opts)(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			require.NoError(t, err)
+
+			assert.InDelta(t, tt.expected, loss.Float64(), 1e-12)
+		})
+	}
+}
+
+func TestUnreduced(t *testing.T) {
+	t.Parallel()
+
+	output := newTestValues(1, 2, 3)
+	values, err := Unreduced(SquaredErrorTerms, Options{ElementWeights: []float64{1, 0, 2}})(
+		output, newTestValues(0, 0, 0),
+	)
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+
+	var got = make([]float64, len(values))
+	for i, v := range values {
+		got[i] = v.Float64()
+	}
+	assert.InDeltaSlice(t, []float64{1, 0, 18}, got, 1e-12)
+
+	// Each term only depends on its own output: d(2 * o ** 2)/do = 4 * o.
+	values[2].Backward()
+	assert.InDelta(t, 0, output[0].Grad(), 1e-12)
+	assert.InDelta(t, 12, output[2].Grad(), 1e-12)
+}
+
+func TestReduceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		criterion   Criterion
+		opts        Options
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "unknown_reduction",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{Reduction: Reduction(42)},
+			output:      []float64{1},
+			expectation: []float64{0},
+			expectedErr: ErrInvalidReduction,
+		},
+		{
+			name:        "element_weights_shape",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ElementWeights: []float64{1}},
+			output:      []float64{1, 2},
+			expectation: []float64{0, 0},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "missing_class_weight",
+			criterion:   SoftmaxCrossEntropyTerms,
+			opts:        Options{ClassWeights: []float64{1}},
+			output:      []float64{0, 0},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "negative_weight",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ElementWeights: []float64{-1}},
+			output:      []float64{1},
+			expectation: []float64{0},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "nan_element_weight",
+			criterion:   SquaredErrorTerms,
+			opts:        Options{ElementWeights: []float64{math.NaN()}},
+			output:      []float64{1},
+			expectation: []float64{0},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "infinite_class_weight",
+			criterion:   SoftmaxCrossEntropyTerms,
+			opts:        Options{ClassWeights: []float64{1, math.Inf(1)}},
+			output:      []float64{0, 0},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "criterion_error",
+			criterion:   SquaredErrorTerms,
+			expectedErr: ErrEmptyOutput,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Reduce(tt.criterion, tt.opts)(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// AbsoluteErrorTerms is the criterion of the absolute error |output - expectation| of each element.
+func AbsoluteErrorTerms(output, expectation []*nn.Value) ([]Term, error) {
+	residuals, err := residuals(output, expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms = make([]Term, len(residuals))
+	for i, r := range residuals {
+		terms[i] = Term{Value: r.Abs(), Class: NoClass}
+	}
+
+	return terms, nil
+}
+
+// MeanAbsoluteError is the mean of |output - expectation|; it grows linearly in the error, so is far less sensitive
+// to outliers than MeanSquaredError.
+func MeanAbsoluteError(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(AbsoluteErrorTerms, Options{})(output, expectation)
+}
+
+// HuberTerms returns the criterion of the Huber loss of each element, which is quadratic for residuals within
+// delta & linear beyond it:
+//
+//	0.5 * r ** 2                  if |r| <= delta
+//	delta * (|r| - 0.5 * delta)   otherwise
+//
+// Delta must be positive.
+func HuberTerms(delta float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		if !(delta > 0) {
+			return nil, fmt.Errorf("huber delta %f must be positive: %w", delta, ErrInvalidParameter)
+		}
+
+		residuals, err := residuals(output, expectation)
+		if err != nil {
+			return nil, err
+		}
+
+		var terms = make([]Term, len(residuals))
+		for i, r := range residuals {
+			var value *nn.Value
+			if math.Abs(r.Float64()) <= delta {
+				value = r.Pow(decimal.NewFromInt(2)).Mul(constant(r, 0.5, "half"))
+			} else {
+				value = r.Abs().Sub(constant(r, 0.5*delta, "huber_offset")).Mul(constant(r, delta, "huber_delta"))
+			}
+
+			terms[i] = Term{Value: value, Class: NoClass}
+		}
+
+		return terms, nil
+	}
+}
+
+// Huber returns the mean of HuberTerms.
+func Huber(delta float64) nn.Losser {
+	return Reduce(HuberTerms(delta), Options{})
+}
+
+// LogCoshTerms is the criterion of log(cosh(output - expectation)) of each element, which behaves like the squared
+// error for small residuals & like the absolute error for large ones, whilst being smooth everywhere. It is
+// computed as |r| + log(1 + exp(-2|r|)) - log(2), so cosh never overflows.
+func LogCoshTerms(output, expectation []*nn.Value) ([]Term, error) {
+	residuals, err := residuals(output, expectation)
+	if err != nil {
+		return nil, err
+	}
+
+	var terms = make([]Term, len(residuals))
+	for i, r := range residuals {
+		abs := r.Abs()
+		softplus, err := abs.Mul(constant(r, -2, "minus_two")).Exp().Add(constant(r, 1, "one")).TryLog()
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+
+		terms[i] = Term{Value: abs.Add(softplus).Sub(constant(r, math.Ln2, "ln2")), Class: NoClass}
+	}
+
+	return terms, nil
+}
+
+// LogCosh is the mean of LogCoshTerms.
+func LogCosh(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(LogCoshTerms, Options{})(output, expectation)
+}
+
+// QuantileTerms returns the criterion of the pinball loss of each element for the quantile q, which must be within
+// (0, 1). Under predictions are weighted by q & over predictions by 1 - q, so minimizing it estimates the q-th
+// quantile of the target; a q of 0.5 is half the absolute error.
+func QuantileTerms(q float64) Criterion {
+	return func(output, expectation []*nn.Value) ([]Term, error) {
+		if !(q > 0 && q < 1) {
+			return nil, fmt.Errorf("quantile %f must be within (0, 1): %w", q, ErrInvalidParameter)
+		}
+
+		residuals, err := residuals(output, expectation)
+		if err != nil {
+			return nil, err
+		}
+
+		var terms = make([]Term, len(residuals))
+		for i, r := range residuals {
+			// The residual is output - expectation, so a non positive residual is an under prediction.
+			var weight = 1 - q
+			if r.Float64() <= 0 {
+				weight = -q
+			}
+
+			terms[i] = Term{Value: r.Mul(constant(r, weight, "quantile")), Class: NoClass}
+		}
+
+		return terms, nil
+	}
+}
+
+// Quantile returns the mean of QuantileTerms.
+func Quantile(q float64) nn.Losser {
+	return Reduce(QuantileTerms(q), Options{})
+}
+
+// residuals validates the shapes of a regression loss & returns output - expectation element wise.
+func residuals(output, expectation []*nn.Value) ([]*nn.Value, error) {
+	if len(output) == 0 {
+		return nil, ErrEmptyOutput
+	}
+
+	if len(output) != len(expectation) {
+		return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+	}
+
+	var out = make([]*nn.Value, len(output))
+	for i := range output {
+		out[i] = output[i].Sub(expectation[i])
+	}
+
+	return out, nil
+}
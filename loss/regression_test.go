@@ -0,0 +1,141 @@
+package loss
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressionLosses(t *testing.T) {
+	t.Parallel()
+
+	// The residuals are 0.5, 3 & 0; the last sits on the kink of every non smooth loss.
+	output, expectation := []float64{1, 3, -2}, []float64{0.5, 0, -2}
+
+	tests := []struct {
+		name          string
+		losser        nn.Losser
+		expected      float64
+		expectedGrads []float64
+	}{
+		{
+			name:          "mean_absolute_error",
+			losser:        MeanAbsoluteError,
+			expected:      3.5 / 3,
+			expectedGrads: []float64{1.0 / 3, 1.0 / 3, 0},
+		},
+		{
+			name:          "huber",
+			losser:        Huber(1),
+			expected:      (0.125 + 2.5) / 3,
+			expectedGrads: []float64{0.5 / 3, 1.0 / 3, 0},
+		},
+		{
+			name:          "log_cosh",
+			losser:        LogCosh,
+			expected:      (math.Log(math.Cosh(0.5)) + math.Log(math.Cosh(3))) / 3,
+			expectedGrads: []float64{math.Tanh(0.5) / 3, math.Tanh(3) / 3, 0},
+		},
+		{
+			name:          "quantile",
+			losser:        Quantile(0.9),
+			expected:      (0.05 + 0.3) / 3,
+			expectedGrads: []float64{0.1 / 3, 0.1 / 3, -0.9 / 3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputValues := newTestValues(output...)
+			loss, err := tt.losser(outputValues, newTestValues(expectation...))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, loss.Float64(), 1e-12)
+
+			loss.Backward()
+			for i, o := range outputValues {
+				assert.InDelta(t, tt.expectedGrads[i], o.Grad(), 1e-12, "output %d", i)
+			}
+		})
+	}
+}
+
+func TestLogCoshLargeResidual(t *testing.T) {
+	t.Parallel()
+
+	// cosh(1000) overflows float64, whereas log(cosh(1000)) = 1000 - log(2).
+	loss, err := LogCosh(newTestValues(1000), newTestValues(0))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1000-math.Ln2, loss.Float64(), 1e-9)
+}
+
+func TestRegressionLossErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		losser      nn.Losser
+		output      []float64
+		expectation []float64
+		expectedErr error
+	}{
+		{
+			name:        "empty_output",
+			losser:      MeanAbsoluteError,
+			expectedErr: ErrEmptyOutput,
+		},
+		{
+			name:        "shape_mismatch",
+			losser:      LogCosh,
+			output:      []float64{1, 2},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidShape,
+		},
+		{
+			name:        "huber_delta",
+			losser:      Huber(0),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "huber_nan_delta",
+			losser:      Huber(math.NaN()),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "quantile_nan",
+			losser:      Quantile(math.NaN()),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+		{
+			name:        "quantile",
+			losser:      Quantile(1),
+			output:      []float64{1},
+			expectation: []float64{1},
+			expectedErr: ErrInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.losser(newTestValues(tt.output...), newTestValues(tt.expectation...))
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package loss
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+// L1 returns a regularizer penalizing lambda * sum(|p|) over the params of the given kinds, or over every param if
+// no kinds are given. It drives params towards exactly zero, so encourages sparse weights.
+func L1(lambda float64, kinds ...nn.Kind) nn.Regularizer {
+	return func(params []*nn.Value) (*nn.Value, error) {
+		return penalty("l1", lambda, selectKinds(params, kinds), func(p *nn.Value) *nn.Value {
+			return p.Abs()
+		})
+	}
+}
+
+// L2 returns a regularizer penalizing lambda * sum(p ** 2) over the params of the given kinds, or over every param
+// if no kinds are given. Its gradient is 2 * lambda * p, so under SGD it is equivalent to a weight decay of
+// 2 * lambda.
+func L2(lambda float64, kinds ...nn.Kind) nn.Regularizer {
+	return func(params []*nn.Value) (*nn.Value, error) {
+		return penalty("l2", lambda, selectKinds(params, kinds), func(p *nn.Value) *nn.Value {
+			return p.Pow(decimal.NewFromInt(2))
+		})
+	}
+}
+
+func penalty(name string, lambda float64, params []*nn.Value, term func(p *nn.Value) *nn.Value) (*nn.Value, error) {
+	if !(lambda >= 0) || math.IsInf(lambda, 1) {
+		return nil, fmt.Errorf("%s lambda %f must be finite & not negative: %w", name, lambda, ErrInvalidParameter)
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("%s penalty has no params: %w", name, ErrEmptyOutput)
+	}
+
+	summation := term(params[0])
+	for _, p := range params[1:] {
+		summation = summation.Add(term(p))
+	}
+
+	return summation.Mul(constant(params[0], lambda, name+"_lambda")), nil
+}
+
+func selectKinds(params []*nn.Value, kinds []nn.Kind) []*nn.Value {
+	if len(kinds) == 0 {
+		return params
+	}
+
+	var out = make([]*nn.Value, 0, len(params))
+	for _, p := range params {
+		for _, kind := range kinds {
+			if p.Kind() == kind {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,87 @@
+package loss
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParam(f float64, kind nn.Kind) *nn.Value {
+	return nn.NewValueFromScalar(nn.Float64Backend.FromFloat64(f), nn.OperationNOOP, kind, "")
+}
+
+func TestRegularizers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		regularizer     nn.Regularizer
+		expectedPenalty float64
+		// expectedGrads are the gradients of the weight & bias respectively.
+		expectedGrads []float64
+	}{
+		{
+			name:            "l1",
+			regularizer:     L1(0.1),
+			expectedPenalty: 0.1 * (2 + 3),
+			expectedGrads:   []float64{-0.1, 0.1},
+		},
+		{
+			name:            "l1_weights",
+			regularizer:     L1(0.1, nn.KindWeight),
+			expectedPenalty: 0.1 * 2,
+			expectedGrads:   []float64{-0.1, 0},
+		},
+		{
+			name:            "l2",
+			regularizer:     L2(0.1),
+			expectedPenalty: 0.1 * (4 + 9),
+			expectedGrads:   []float64{-0.4, 0.6},
+		},
+		{
+			name:            "l2_weights",
+			regularizer:     L2(0.1, nn.KindWeight),
+			expectedPenalty: 0.1 * 4,
+			expectedGrads:   []float64{-0.4, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := []*nn.Value{newTestParam(-2, nn.KindWeight), newTestParam(3, nn.KindBias)}
+
+			penalty, err := tt.regularizer(params)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expectedPenalty, penalty.Float64(), 1e-12)
+
+			penalty.Backward()
+			assert.InDelta(t, tt.expectedGrads[0], params[0].Grad(), 1e-12)
+			assert.InDelta(t, tt.expectedGrads[1], params[1].Grad(), 1e-12)
+		})
+	}
+}
+
+func TestRegularizerErrors(t *testing.T) {
+	t.Parallel()
+
+	params := []*nn.Value{newTestParam(1, nn.KindBias)}
+
+	_, err := L2(-1)(params)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	_, err = L2(math.NaN())(params)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	_, err = L1(math.Inf(1))(params)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	_, err = L1(0.1, nn.KindWeight)(params)
+	assert.ErrorIs(t, err, ErrEmptyOutput)
+}
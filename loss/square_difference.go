@@ -1,51 +1,27 @@
 package loss
 
 import (
-	"errors"
-	"fmt"
 	"grad2go/nn"
 
 	"github.com/shopspring/decimal"
 )
 
-var (
-	ErrInvalidShape = errors.New("invalid shape")
-	ErrEmptyOutput  = errors.New("empty output")
-)
-
-func MeanSquaredError(output, expectation []*nn.Value) (*nn.Value, error) {
-	if len(output) == 0 {
-		return nil, ErrEmptyOutput
+// SquaredErrorTerms is the criterion of the squared error (output - expectation) ** 2 of each element.
+func SquaredErrorTerms(output, expectation []*nn.Value) ([]Term, error) {
+	residuals, err := residuals(output, expectation)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(output) != len(expectation) {
-		return nil, fmt.Errorf("expected shape %d, got %d: %w", len(output), len(expectation), ErrInvalidShape)
+	var terms = make([]Term, len(residuals))
+	for i, r := range residuals {
+		terms[i] = Term{Value: r.Pow(decimal.NewFromInt(2)), Class: NoClass}
 	}
 
-	// 1 / N * sum((y - yHat) ** 2).
-	var intermediary = make([]*nn.Value, len(output))
-	for i := 0; i < len(output); i++ {
-		y, yHat := output[i], expectation[i]
-
-		diff := y.Sub(yHat)
-		squaredDiff := diff.Pow(decimal.NewFromInt(2))
-
-		intermediary[i] = squaredDiff
-	}
-
-	summation := intermediary[0]
-	for i := 1; i < len(intermediary); i++ {
-		next := intermediary[i]
-		summation = summation.Add(next)
-	}
-
-	divisor := nn.NewValue(decimal.NewFromInt(
-		int64(len(output))),
-		nn.OperationDiv,
-		nn.KindValue,
-		"mse_divisor",
-	)
+	return terms, nil
+}
 
-	out := summation.Div(divisor)
-	return out, nil
+// MeanSquaredError is 1 / N * sum((output - expectation) ** 2).
+func MeanSquaredError(output, expectation []*nn.Value) (*nn.Value, error) {
+	return Reduce(SquaredErrorTerms, Options{})(output, expectation)
 }
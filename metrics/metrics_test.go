@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveLearningRateGauge(t *testing.T) {
+	require.ErrorIs(t, ObserveLearningRateGauge(0.5, nil), ErrMetricsNotInitialized)
+
+	require.NoError(t, Init(Config{Namespace: "grad2go", Subsystem: "metrics_test"}))
+	require.NoError(t, ObserveLearningRateGauge(0.5, nil))
+
+	const expected = `
+# HELP grad2go_metrics_test_learning_rate_gauge The gauge for the learning rate of the neural network
+# TYPE grad2go_metrics_test_learning_rate_gauge gauge
+grad2go_metrics_test_learning_rate_gauge 0.5
+`
+	assert.NoError(t, testutil.GatherAndCompare(
+		registry, strings.NewReader(expected), "grad2go_metrics_test_learning_rate_gauge",
+	))
+
+	// The gauge has no label names, so label values are rejected.
+	assert.Error(t, ObserveLearningRateGauge(0.5, []string{"model"}))
+}
@@ -0,0 +1,150 @@
+package nn
+
+import "fmt"
+
+// defaultLeakyReLuSlope is the slope applied to negative inputs by ActivationLeakyReLu.
+const defaultLeakyReLuSlope = 0.01
+
+type Activation int32
+
+const (
+	// ActivationDefault resolves to ReLu for hidden layers & Linear for the output layer.
+	ActivationDefault Activation = iota
+	ActivationLinear
+	ActivationReLu
+	ActivationLeakyReLu
+	ActivationTanh
+	ActivationSigmoid
+	// ActivationSoftmax normalizes the outputs of the whole layer, rather than each neuron independently.
+	ActivationSoftmax
+)
+
+// String implements the stringer interface.
+func (a Activation) String() string {
+	switch a {
+	case ActivationDefault:
+		return "default"
+	case ActivationLinear:
+		return "linear"
+	case ActivationReLu:
+		return "relu"
+	case ActivationLeakyReLu:
+		return "leaky_relu"
+	case ActivationTanh:
+		return "tanh"
+	case ActivationSigmoid:
+		return "sigmoid"
+	case ActivationSoftmax:
+		return "softmax"
+	default:
+		return "unknown"
+	}
+}
+
+// apply applies an element wise activation to v; layer wide activations such as softmax leave v untouched.
+func (a Activation) apply(v *Value) *Value {
+	switch a {
+	case ActivationReLu:
+		return v.ReLu()
+	case ActivationLeakyReLu:
+		return v.LeakyReLu(defaultLeakyReLuSlope)
+	case ActivationTanh:
+		return v.Tanh()
+	case ActivationSigmoid:
+		return v.Sigmoid()
+	default:
+		return v
+	}
+}
+
+// resolveActivation returns the activation for the layer at index, out of a total number of layers.
+func resolveActivation(activations []Activation, index, layers int) Activation {
+	if index < len(activations) && activations[index] != ActivationDefault {
+		return activations[index]
+	}
+
+	if index == layers-1 {
+		return ActivationLinear
+	}
+
+	return ActivationReLu
+}
+
+// LogSoftmax returns the logarithm of the softmax of the values, computed as (x - max) - log(sum(exp(x - max))) so
+// that it stays finite where the softmax itself would underflow to zero.
+func LogSoftmax(values []*Value) ([]*Value, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	shift := maxShift(values)
+
+	var shifted = make([]*Value, len(values))
+	for i, v := range values {
+		shifted[i] = v.Sub(shift)
+	}
+
+	sum := shifted[0].Exp()
+	for _, v := range shifted[1:] {
+		sum = sum.Add(v.Exp())
+	}
+
+	// The sum is at least one unless a value is NaN, since the maximum value exponentiates to one.
+	logSum, err := sum.TryLog()
+	if err != nil {
+		return nil, fmt.Errorf("log softmax: %w", err)
+	}
+
+	var out = make([]*Value, len(values))
+	for i, v := range shifted {
+		out[i] = v.Sub(logSum)
+	}
+
+	return out, nil
+}
+
+// maxShift returns a no-grad constant holding the maximum of the values, which is subtracted before exponentiating;
+// being no-grad, it never makes an otherwise no-grad graph require grad.
+func maxShift(values []*Value) *Value {
+	var maxValue = values[0].Float64()
+	for _, v := range values[1:] {
+		if f := v.Float64(); f > maxValue {
+			maxValue = f
+		}
+	}
+
+	return newValueWithContext(values[0].Backend().FromFloat64(maxValue), OperationNOOP, KindValue, nil).NoGrad()
+}
+
+// Softmax normalizes the values into a probability distribution. The maximum value is subtracted from every
+// input before exponentiating for numerical stability; softmax is invariant to the shift so gradients are exact.
+func Softmax(values []*Value) ([]*Value, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	shift := maxShift(values)
+
+	var exps = make([]*Value, len(values))
+	for i, v := range values {
+		exps[i] = v.Sub(shift).Exp()
+	}
+
+	sum := exps[0]
+	for _, e := range exps[1:] {
+		sum = sum.Add(e)
+	}
+
+	var out = make([]*Value, len(values))
+	for i, e := range exps {
+		// The sum is at least one unless a value is NaN, since the maximum value exponentiates to one.
+		p, err := e.TryDiv(sum)
+		if err != nil {
+			return nil, fmt.Errorf("softmax: %w", err)
+		}
+
+		out[i] = p
+	}
+
+	return out, nil
+}
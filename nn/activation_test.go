@@ -0,0 +1,140 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveActivation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		activations []Activation
+		expected    []Activation
+	}{
+		{
+			name:     "defaults",
+			expected: []Activation{ActivationReLu, ActivationReLu, ActivationLinear},
+		},
+		{
+			name:        "explicit",
+			activations: []Activation{ActivationTanh, ActivationLeakyReLu, ActivationSoftmax},
+			expected:    []Activation{ActivationTanh, ActivationLeakyReLu, ActivationSoftmax},
+		},
+		{
+			name:        "partial",
+			activations: []Activation{ActivationSigmoid, ActivationDefault},
+			expected:    []Activation{ActivationSigmoid, ActivationReLu, ActivationLinear},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mlp := NewMLPFromConfig(NeuralNetworkConfig{
+				InputShape:  2,
+				Shape:       []int{3, 3, 2},
+				Activations: tt.activations,
+			})
+
+			var got []Activation
+			for _, l := range mlp.layers {
+				got = append(got, l.activation)
+
+				for _, n := range l.neurons {
+					if l.activation == ActivationSoftmax {
+						assert.Equal(t, ActivationSoftmax, n.activation)
+						continue
+					}
+
+					assert.Equal(t, l.activation, n.activation)
+				}
+			}
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSoftmax(t *testing.T) {
+	t.Parallel()
+
+	// Large logits would overflow without the max shift.
+	logits := newTestInputs(1000.0, 1001.0, 1002.0)
+
+	probabilities, err := Softmax(logits)
+	require.NoError(t, err)
+	require.Len(t, probabilities, 3)
+
+	var sum float64
+	for _, p := range probabilities {
+		assert.False(t, math.IsNaN(p.Float64()))
+		sum += p.Float64()
+	}
+	assert.InDelta(t, 1.0, sum, 1e-12)
+
+	e0, e1, e2 := math.Exp(-2), math.Exp(-1), 1.0
+	assert.InDelta(t, e0/(e0+e1+e2), probabilities[0].Float64(), 1e-12)
+
+	// d(p_2)/d(x_i) = p_2 * (1[i == 2] - p_i).
+	probabilities[2].Backward()
+	p := []float64{probabilities[0].Float64(), probabilities[1].Float64(), probabilities[2].Float64()}
+	assert.InDelta(t, -p[2]*p[0], logits[0].Grad(), 1e-9)
+	assert.InDelta(t, -p[2]*p[1], logits[1].Grad(), 1e-9)
+	assert.InDelta(t, p[2]*(1-p[2]), logits[2].Grad(), 1e-9)
+}
+
+func TestLogSoftmax(t *testing.T) {
+	t.Parallel()
+
+	// The softmax of the first logit underflows to zero, whose log would be -Inf.
+	logits := newTestInputs(-1000.0, 0.0, 1.0)
+
+	logProbabilities, err := LogSoftmax(logits)
+	require.NoError(t, err)
+	require.Len(t, logProbabilities, 3)
+
+	logSum := math.Log(math.Exp(-1001) + math.Exp(-1) + 1)
+	assert.InDelta(t, -1001-logSum, logProbabilities[0].Float64(), 1e-9)
+	assert.InDelta(t, -1-logSum, logProbabilities[1].Float64(), 1e-9)
+	assert.InDelta(t, -logSum, logProbabilities[2].Float64(), 1e-9)
+
+	// d(log p_2)/d(x_i) = 1[i == 2] - p_i.
+	logProbabilities[2].Backward()
+	for i, l := range logits {
+		var indicator float64
+		if i == 2 {
+			indicator = 1
+		}
+
+		assert.InDelta(t, indicator-math.Exp(logProbabilities[i].Float64()), l.Grad(), 1e-9)
+	}
+}
+
+func TestLayerSoftmaxForward(t *testing.T) {
+	t.Parallel()
+
+	mlp := NewMLPFromConfig(NeuralNetworkConfig{
+		InputShape:  2,
+		Shape:       []int{4},
+		Activations: []Activation{ActivationSoftmax},
+	})
+
+	out, err := mlp.Forward(newTestInputs(0.3, -0.7))
+	require.NoError(t, err)
+	require.Len(t, out, 4)
+
+	var sum float64
+	for _, o := range out {
+		assert.Greater(t, o.Float64(), 0.0)
+		sum += o.Float64()
+	}
+	assert.InDelta(t, 1.0, sum, 1e-12)
+}
@@ -0,0 +1,230 @@
+package nn
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// Float64Backend stores values as plain float64; it is fast and allocation light, so is the default for
+	// training.
+	Float64Backend Backend = float64Backend{}
+	// DecimalBackend stores values as arbitrary precision decimals; it is slow but exact, so is useful when
+	// debugging.
+	DecimalBackend Backend = decimalBackend{}
+
+	// DefaultBackend is the backend used for values created without an explicit backend.
+	DefaultBackend = Float64Backend
+)
+
+// Backend creates scalars of a given numeric representation.
+type Backend interface {
+	Name() string
+	FromFloat64(f float64) Scalar
+	FromDecimal(d decimal.Decimal) Scalar
+}
+
+// Scalar is a single number held by a Value. Binary operations return a scalar of the receiver's backend,
+// converting the other operand if it was created by a different backend.
+type Scalar interface {
+	Add(other Scalar) Scalar
+	Sub(other Scalar) Scalar
+	Mul(other Scalar) Scalar
+	Div(other Scalar) Scalar
+	Pow(exponent Scalar) Scalar
+	Neg() Scalar
+	Abs() Scalar
+	Cmp(other Scalar) int
+	Sign() int
+	IsZero() bool
+	// IsFinite reports whether the scalar is neither NaN nor infinite.
+	IsFinite() bool
+	Float64() float64
+	// Decimal returns the scalar as a decimal; decimals have no NaN or infinity, so it returns zero for a
+	// non-finite scalar & callers must check IsFinite first.
+	Decimal() decimal.Decimal
+	Backend() Backend
+}
+
+// applyFloat64 evaluates fn in float64 space & converts the result back to the backend of s; it is used for
+// the transcendental functions which have no exact decimal representation.
+func applyFloat64(s Scalar, fn func(float64) float64) Scalar {
+	return s.Backend().FromFloat64(fn(s.Float64()))
+}
+
+type float64Backend struct{}
+
+func (float64Backend) Name() string                         { return "float64" }
+func (float64Backend) FromFloat64(f float64) Scalar         { return float64Scalar(f) }
+func (float64Backend) FromDecimal(d decimal.Decimal) Scalar { return float64Scalar(d.InexactFloat64()) }
+
+type float64Scalar float64
+
+func (s float64Scalar) Add(other Scalar) Scalar { return s + float64Scalar(other.Float64()) }
+func (s float64Scalar) Sub(other Scalar) Scalar { return s - float64Scalar(other.Float64()) }
+func (s float64Scalar) Mul(other Scalar) Scalar { return s * float64Scalar(other.Float64()) }
+func (s float64Scalar) Div(other Scalar) Scalar { return s / float64Scalar(other.Float64()) }
+func (s float64Scalar) Neg() Scalar             { return -s }
+func (s float64Scalar) Abs() Scalar             { return float64Scalar(math.Abs(float64(s))) }
+func (s float64Scalar) IsZero() bool            { return s == 0 }
+func (s float64Scalar) IsFinite() bool          { return isFinite(float64(s)) }
+func (s float64Scalar) Float64() float64        { return float64(s) }
+func (s float64Scalar) Decimal() decimal.Decimal {
+	if !s.IsFinite() {
+		return decimal.Zero
+	}
+
+	return decimal.NewFromFloat(float64(s))
+}
+func (s float64Scalar) Backend() Backend { return Float64Backend }
+
+func (s float64Scalar) Pow(exponent Scalar) Scalar {
+	return float64Scalar(math.Pow(float64(s), exponent.Float64()))
+}
+
+func (s float64Scalar) Cmp(other Scalar) int {
+	o := float64Scalar(other.Float64())
+	switch {
+	case s < o:
+		return -1
+	case s > o:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s float64Scalar) Sign() int {
+	switch {
+	case s < 0:
+		return -1
+	case s > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type decimalBackend struct{}
+
+func (decimalBackend) Name() string { return "decimal" }
+
+// FromFloat64 converts f to a decimal; NaN & infinities have no decimal representation, so they are held as
+// float64 instead.
+func (decimalBackend) FromFloat64(f float64) Scalar {
+	if !isFinite(f) {
+		return decimalScalar{nonFinite: f, isNonFinite: true}
+	}
+
+	return decimalScalar{d: decimal.NewFromFloat(f)}
+}
+
+func (decimalBackend) FromDecimal(d decimal.Decimal) Scalar { return decimalScalar{d: d} }
+
+type decimalScalar struct {
+	d decimal.Decimal
+	// nonFinite holds NaN or an infinity, which decimal cannot represent; d is unused while isNonFinite is set.
+	nonFinite   float64
+	isNonFinite bool
+}
+
+func (s decimalScalar) Add(other Scalar) Scalar {
+	return s.apply(other, decimal.Decimal.Add, func(a, b float64) float64 { return a + b })
+}
+
+func (s decimalScalar) Sub(other Scalar) Scalar {
+	return s.apply(other, decimal.Decimal.Sub, func(a, b float64) float64 { return a - b })
+}
+
+func (s decimalScalar) Mul(other Scalar) Scalar {
+	return s.apply(other, decimal.Decimal.Mul, func(a, b float64) float64 { return a * b })
+}
+
+func (s decimalScalar) Div(other Scalar) Scalar {
+	// Decimal panics on division by zero, whereas float64 gives an infinity or NaN.
+	if other.IsZero() {
+		return DecimalBackend.FromFloat64(s.Float64() / other.Float64())
+	}
+
+	return s.apply(other, decimal.Decimal.Div, func(a, b float64) float64 { return a / b })
+}
+
+func (s decimalScalar) Neg() Scalar {
+	if s.isNonFinite {
+		return DecimalBackend.FromFloat64(-s.nonFinite)
+	}
+
+	return decimalScalar{d: s.d.Neg()}
+}
+
+func (s decimalScalar) Abs() Scalar {
+	if s.isNonFinite {
+		return DecimalBackend.FromFloat64(math.Abs(s.nonFinite))
+	}
+
+	return decimalScalar{d: s.d.Abs()}
+}
+
+func (s decimalScalar) Cmp(other Scalar) int {
+	if s.isNonFinite || !other.IsFinite() {
+		return float64Scalar(s.Float64()).Cmp(other)
+	}
+
+	return s.d.Cmp(other.Decimal())
+}
+
+func (s decimalScalar) Sign() int {
+	if s.isNonFinite {
+		return float64Scalar(s.nonFinite).Sign()
+	}
+
+	return s.d.Sign()
+}
+
+func (s decimalScalar) IsZero() bool     { return !s.isNonFinite && s.d.IsZero() }
+func (s decimalScalar) IsFinite() bool   { return !s.isNonFinite }
+func (s decimalScalar) Backend() Backend { return DecimalBackend }
+
+func (s decimalScalar) Float64() float64 {
+	if s.isNonFinite {
+		return s.nonFinite
+	}
+
+	return s.d.InexactFloat64()
+}
+
+func (s decimalScalar) Decimal() decimal.Decimal {
+	if s.isNonFinite {
+		return decimal.Zero
+	}
+
+	return s.d
+}
+
+func (s decimalScalar) Pow(exponent Scalar) Scalar {
+	// Decimal only supports finite, integer powers exactly & panics dividing by a zero base raised to a negative
+	// power; fall back to float64 space otherwise.
+	if s.isNonFinite || !exponent.IsFinite() || !exponent.Decimal().IsInteger() || s.IsZero() && exponent.Sign() < 0 {
+		return DecimalBackend.FromFloat64(math.Pow(s.Float64(), exponent.Float64()))
+	}
+
+	return decimalScalar{d: s.d.Pow(exponent.Decimal())}
+}
+
+// apply evaluates exact on the decimals of s & other, or inexact in float64 space if either is non-finite.
+func (s decimalScalar) apply(
+	other Scalar,
+	exact func(a, b decimal.Decimal) decimal.Decimal,
+	inexact func(a, b float64) float64,
+) Scalar {
+	if s.isNonFinite || !other.IsFinite() {
+		return DecimalBackend.FromFloat64(inexact(s.Float64(), other.Float64()))
+	}
+
+	return decimalScalar{d: exact(s.d, other.Decimal())}
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
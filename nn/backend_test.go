@@ -0,0 +1,227 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendsAgree(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		backend Backend
+	}{
+		{
+			name:    "float64",
+			backend: Float64Backend,
+		},
+		{
+			name:    "decimal",
+			backend: DecimalBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := NewValueFromScalar(tt.backend.FromFloat64(1.5), OperationNOOP, KindInput, "a")
+			b := NewValueFromScalar(tt.backend.FromFloat64(-2.0), OperationNOOP, KindInput, "b")
+
+			// (a * b + a ** 2) / b, then through tanh.
+			out := a.Mul(b).Add(a.Pow(decimal.NewFromInt(2))).Div(b).Tanh()
+			out.Backward()
+
+			assert.Equal(t, tt.backend, out.Backend())
+			assert.InDelta(t, 0.358357398350786, out.Float64(), 1e-9)
+			assert.InDelta(t, -0.435789987523628, a.Grad(), 1e-9)
+			assert.InDelta(t, -0.490263735964082, b.Grad(), 1e-9)
+		})
+	}
+}
+
+func TestDecimalBackendIsExact(t *testing.T) {
+	t.Parallel()
+
+	a := NewValueFromScalar(DecimalBackend.FromDecimal(decimal.RequireFromString("0.1")), OperationNOOP, KindInput, "a")
+	b := NewValueFromScalar(DecimalBackend.FromDecimal(decimal.RequireFromString("0.2")), OperationNOOP, KindInput, "b")
+
+	out := a.Add(b)
+	assert.True(t, out.data.Decimal().Equal(decimal.RequireFromString("0.3")))
+}
+
+func TestMixedBackendsUseReceiver(t *testing.T) {
+	t.Parallel()
+
+	a := NewValueFromScalar(DecimalBackend.FromFloat64(2.0), OperationNOOP, KindInput, "a")
+	b := NewValueFromScalar(Float64Backend.FromFloat64(3.0), OperationNOOP, KindInput, "b")
+
+	assert.Equal(t, DecimalBackend, a.Mul(b).Backend())
+	assert.Equal(t, Float64Backend, b.Mul(a).Backend())
+}
+
+func TestNewMLPFromConfigBackend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		backend         Backend
+		expectedBackend Backend
+	}{
+		{
+			name:            "default",
+			expectedBackend: DefaultBackend,
+		},
+		{
+			name:            "decimal",
+			backend:         DecimalBackend,
+			expectedBackend: DecimalBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mlp := NewMLPFromConfig(NeuralNetworkConfig{
+				InputShape: 2,
+				Shape:      []int{4, 1},
+				Backend:    tt.backend,
+			})
+
+			params := mlp.Parameters()
+			require.Len(t, params, 4*(2+1)+1*(4+1))
+
+			for _, p := range params {
+				assert.Equal(t, tt.expectedBackend, p.Backend())
+			}
+
+			out, err := mlp.Forward(newTestInputs(0.5, -0.5))
+			require.NoError(t, err)
+			assert.Len(t, out, 1)
+		})
+	}
+}
+
+func TestBackendsNonFinite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		backend  Backend
+		op       func(backend Backend) *Value
+		expected float64
+	}{
+		{
+			name:    "float64 exp overflow",
+			backend: Float64Backend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(1000), OperationNOOP, KindInput, "x").Exp()
+			},
+			expected: math.Inf(1),
+		},
+		{
+			name:    "decimal exp overflow",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(1000), OperationNOOP, KindInput, "x").Exp()
+			},
+			expected: math.Inf(1),
+		},
+		{
+			name:    "decimal fractional power of negative",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(-2), OperationNOOP, KindInput, "x").Pow(decimal.NewFromFloat(0.5))
+			},
+			expected: math.NaN(),
+		},
+		{
+			name:    "decimal negative power of zero",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(0), OperationNOOP, KindInput, "x").Pow(decimal.NewFromInt(-1))
+			},
+			expected: math.Inf(1),
+		},
+		{
+			name:    "float64 negative power of zero",
+			backend: Float64Backend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(0), OperationNOOP, KindInput, "x").Pow(decimal.NewFromInt(-1))
+			},
+			expected: math.Inf(1),
+		},
+		{
+			name:    "decimal add nan float64",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				a := NewValueFromScalar(backend.FromFloat64(1), OperationNOOP, KindInput, "a")
+				b := NewValueFromScalar(Float64Backend.FromFloat64(math.NaN()), OperationNOOP, KindInput, "b")
+				return a.Add(b)
+			},
+			expected: math.NaN(),
+		},
+		{
+			name:    "decimal infinity minus infinity",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				a := NewValueFromScalar(backend.FromFloat64(math.Inf(1)), OperationNOOP, KindInput, "a")
+				return a.Sub(a)
+			},
+			expected: math.NaN(),
+		},
+		{
+			name:    "decimal negated infinity",
+			backend: DecimalBackend,
+			op: func(backend Backend) *Value {
+				return NewValueFromScalar(backend.FromFloat64(math.Inf(1)), OperationNOOP, KindInput, "x").Neg()
+			},
+			expected: math.Inf(-1),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out *Value
+			require.NotPanics(t, func() { out = tt.op(tt.backend) })
+
+			assert.Equal(t, tt.backend, out.Backend())
+			assert.False(t, out.data.IsFinite())
+			assert.True(t, out.data.Decimal().IsZero())
+
+			if math.IsNaN(tt.expected) {
+				assert.True(t, math.IsNaN(out.Float64()), "got %v, expected NaN", out.Float64())
+				return
+			}
+
+			assert.Equal(t, tt.expected, out.Float64())
+			assert.Equal(t, int(math.Copysign(1, tt.expected)), out.data.Sign())
+		})
+	}
+}
+
+func TestDecimalBackendCompareNonFinite(t *testing.T) {
+	t.Parallel()
+
+	inf := DecimalBackend.FromFloat64(math.Inf(1))
+	one := DecimalBackend.FromFloat64(1)
+
+	assert.Equal(t, 1, inf.Cmp(one))
+	assert.Equal(t, -1, one.Cmp(inf))
+	assert.False(t, inf.IsZero())
+	assert.True(t, one.IsFinite())
+}
@@ -0,0 +1,104 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidClipThreshold = errors.New("invalid gradient clip threshold")
+
+// GradientClipper clips the gradients of the params in place & returns their global L2 norm from before clipping.
+// A NeuralNetwork applies its clipper after the backward pass & before the optimizer step.
+type GradientClipper func(params []*Value) float64
+
+// ClipByValue returns a clipper which applies ClipGradValue; clip must be positive.
+func ClipByValue(clip float64) (GradientClipper, error) {
+	if err := validateClipThreshold(clip); err != nil {
+		return nil, err
+	}
+
+	return func(params []*Value) float64 {
+		norm := gradNorm(params)
+		clipGradValue(params, clip)
+
+		return norm
+	}, nil
+}
+
+// ClipByGlobalNorm returns a clipper which applies ClipGradNorm; maxNorm must be positive.
+func ClipByGlobalNorm(maxNorm float64) (GradientClipper, error) {
+	if err := validateClipThreshold(maxNorm); err != nil {
+		return nil, err
+	}
+
+	return func(params []*Value) float64 {
+		return clipGradNorm(params, maxNorm)
+	}, nil
+}
+
+// ClipGradValue clamps the gradient of each param to [-clip, clip]; clip must be positive.
+func ClipGradValue(params []*Value, clip float64) error {
+	if err := validateClipThreshold(clip); err != nil {
+		return err
+	}
+
+	clipGradValue(params, clip)
+
+	return nil
+}
+
+// ClipGradNorm rescales the gradients of the params so that their global L2 norm, i.e the norm of all gradients
+// taken together as a single vector, is at most maxNorm; maxNorm must be positive. The direction of the update is
+// preserved, unlike clipping by value. The norm before clipping is returned, which is useful to monitor.
+func ClipGradNorm(params []*Value, maxNorm float64) (float64, error) {
+	if err := validateClipThreshold(maxNorm); err != nil {
+		return 0, err
+	}
+
+	return clipGradNorm(params, maxNorm), nil
+}
+
+// validateClipThreshold rejects thresholds which aren't positive, including NaN: a negative threshold would flip
+// gradients into gradient ascent & a zero one would silently discard them.
+func validateClipThreshold(threshold float64) error {
+	if !(threshold > 0) {
+		return fmt.Errorf("threshold %f must be positive: %w", threshold, ErrInvalidClipThreshold)
+	}
+
+	return nil
+}
+
+func clipGradValue(params []*Value, clip float64) {
+	for _, p := range params {
+		grad := p.Grad()
+		if clipped := math.Max(-clip, math.Min(clip, grad)); clipped != grad {
+			p.SetGrad(clipped)
+		}
+	}
+}
+
+func clipGradNorm(params []*Value, maxNorm float64) float64 {
+	norm := gradNorm(params)
+	if norm <= maxNorm {
+		return norm
+	}
+
+	scale := maxNorm / norm
+	for _, p := range params {
+		p.SetGrad(p.Grad() * scale)
+	}
+
+	return norm
+}
+
+// gradNorm returns the global L2 norm of the gradients of the params.
+func gradNorm(params []*Value) float64 {
+	var sumOfSquares float64
+	for _, p := range params {
+		grad := p.Grad()
+		sumOfSquares += grad * grad
+	}
+
+	return math.Sqrt(sumOfSquares)
+}
@@ -0,0 +1,172 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParamsWithGrads(grads ...float64) []*Value {
+	params := newTestInputs(make([]float64, len(grads))...)
+	for i, g := range grads {
+		params[i].SetGrad(g)
+	}
+
+	return params
+}
+
+func grads(params []*Value) []float64 {
+	var out = make([]float64, len(params))
+	for i, p := range params {
+		out[i] = p.Grad()
+	}
+
+	return out
+}
+
+func TestClipGradValue(t *testing.T) {
+	t.Parallel()
+
+	params := newTestParamsWithGrads(-3, -0.5, 0, 0.5, 3)
+	require.NoError(t, ClipGradValue(params, 1))
+
+	assert.Equal(t, []float64{-1, -0.5, 0, 0.5, 1}, grads(params))
+}
+
+func TestClipGradNorm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		grads         []float64
+		maxNorm       float64
+		expectedNorm  float64
+		expectedGrads []float64
+	}{
+		{
+			name:          "clipped",
+			grads:         []float64{3, -4},
+			maxNorm:       1,
+			expectedNorm:  5,
+			expectedGrads: []float64{0.6, -0.8},
+		},
+		{
+			name:          "within_norm",
+			grads:         []float64{3, -4},
+			maxNorm:       10,
+			expectedNorm:  5,
+			expectedGrads: []float64{3, -4},
+		},
+		{
+			name:          "zero",
+			grads:         []float64{0, 0},
+			maxNorm:       1,
+			expectedNorm:  0,
+			expectedGrads: []float64{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := newTestParamsWithGrads(tt.grads...)
+			norm, err := ClipGradNorm(params, tt.maxNorm)
+			require.NoError(t, err)
+
+			assert.InDelta(t, tt.expectedNorm, norm, 1e-12)
+			assert.InDeltaSlice(t, tt.expectedGrads, grads(params), 1e-12)
+		})
+	}
+}
+
+func TestClipInvalidThreshold(t *testing.T) {
+	t.Parallel()
+
+	for _, threshold := range []float64{-1, 0, math.NaN()} {
+		params := newTestParamsWithGrads(3, -4)
+
+		_, err := ClipByValue(threshold)
+		assert.ErrorIs(t, err, ErrInvalidClipThreshold)
+
+		_, err = ClipByGlobalNorm(threshold)
+		assert.ErrorIs(t, err, ErrInvalidClipThreshold)
+
+		assert.ErrorIs(t, ClipGradValue(params, threshold), ErrInvalidClipThreshold)
+
+		_, err = ClipGradNorm(params, threshold)
+		assert.ErrorIs(t, err, ErrInvalidClipThreshold)
+
+		assert.Equal(t, []float64{3, -4}, grads(params))
+	}
+}
+
+func TestNeuralNetworkGradientClipper(t *testing.T) {
+	t.Parallel()
+
+	var stepped []float64
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{2, 1},
+		Seed:       1,
+	}, OptimizerFunc(func(params []*Value) {
+		stepped = grads(params)
+	}), sumLosser)
+	clip, err := ClipByValue(1e-3)
+	require.NoError(t, err)
+	net.GradientClipper = clip
+
+	_, err = net.Step(newTestInputs(100, -100), nil)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, stepped)
+	for _, g := range stepped {
+		assert.LessOrEqual(t, g, 1e-3)
+		assert.GreaterOrEqual(t, g, -1e-3)
+	}
+}
+
+func TestClipByValueReturnsNormBeforeClipping(t *testing.T) {
+	t.Parallel()
+
+	params := newTestParamsWithGrads(3, -4)
+
+	clip, err := ClipByValue(1)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 5, clip(params), 1e-12)
+	assert.Equal(t, []float64{1, -1}, grads(params))
+}
+
+func TestNeuralNetworkGradientNorm(t *testing.T) {
+	t.Parallel()
+
+	var stepped []float64
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{2, 1},
+		Seed:       1,
+	}, OptimizerFunc(func(params []*Value) {
+		stepped = grads(params)
+	}), sumLosser)
+	assert.Zero(t, net.GradientNorm())
+
+	var preClipNorm float64
+	clip, err := ClipByGlobalNorm(1e-3)
+	require.NoError(t, err)
+	net.GradientClipper = func(params []*Value) float64 {
+		preClipNorm = gradNorm(params)
+		return clip(params)
+	}
+
+	_, err = net.Step(newTestInputs(100, -100), nil)
+	require.NoError(t, err)
+
+	require.Greater(t, preClipNorm, 1e-3)
+	assert.InDelta(t, preClipNorm, net.GradientNorm(), 1e-12)
+	assert.InDelta(t, 1e-3, gradNorm(newTestParamsWithGrads(stepped...)), 1e-12)
+}
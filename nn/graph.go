@@ -1,12 +1,17 @@
 package nn
 
 import (
+	"errors"
 	"fmt"
 	"grad2go/graph"
 	"strconv"
 	"strings"
 )
 
+// ErrNonFiniteValue is returned when exporting a graph holding a NaN or infinite value or gradient, neither of which
+// a graph node can represent.
+var ErrNonFiniteValue = errors.New("non-finite value")
+
 func BuildGraphFromRootValue(g graph.Grapher, root *Value) error {
 	if g == nil {
 		return fmt.Errorf("graph nil; cannot build with an empty graph")
@@ -24,16 +29,14 @@ func BuildGraphFromRootValue(g graph.Grapher, root *Value) error {
 	setOfGraphNodes := make(map[string]*graph.Node)
 	setOfGraphEdges := make(map[edgeID][2]*graph.Node)
 
-	// DFS.
-	var build func(node *Value)
-	build = func(node *Value) {
+	for _, node := range root.Topo() {
 		// Add original value as node.
-		if _, ok := setOfGraphNodes[node.ID()]; ok {
-			return
+		workingNode, err := marshalValueToNode(node)
+		if err != nil {
+			return err
 		}
-		setOfGraphNodes[node.ID()] = marshalValueToNode(node)
+		setOfGraphNodes[node.ID()] = workingNode
 
-		var workingNode = marshalValueToNode(node)
 		if node.operation.String() != "noop" {
 			// Create "operand" node & inject.
 			cp := deepCopy(workingNode)
@@ -56,15 +59,15 @@ func BuildGraphFromRootValue(g graph.Grapher, root *Value) error {
 
 			if _, ok := setOfGraphEdges[eID]; !ok {
 				v := workingNode
-				u := marshalValueToNode(child)
+				u, err := marshalValueToNode(child)
+				if err != nil {
+					return err
+				}
 
 				setOfGraphEdges[eID] = [2]*graph.Node{v, u}
 			}
-
-			build(child)
 		}
 	}
-	build(root)
 
 	// Build nodes.
 	for _, node := range setOfGraphNodes {
@@ -97,7 +100,11 @@ func BuildGraphVizLayersString(layers int) string {
 	return strings.Join(intermediary, ":")
 }
 
-func marshalValueToNode(v *Value) *graph.Node {
+func marshalValueToNode(v *Value) (*graph.Node, error) {
+	if !v.data.IsFinite() || !v.grad.IsFinite() {
+		return nil, fmt.Errorf("node %s has data %v & grad %v: %w", v.ID(), v.Float64(), v.Grad(), ErrNonFiniteValue)
+	}
+
 	var kind graph.NodeKind
 	switch v.Kind() {
 	case KindBias:
@@ -111,19 +118,19 @@ func marshalValueToNode(v *Value) *graph.Node {
 	}
 
 	var layer string
-	if layerValue := v.layer(); layerValue >= 0 {
+	if layerValue := v.Layer(); layerValue >= 0 {
 		layer = strconv.Itoa(layerValue)
 	}
 
 	return &graph.Node{
-		Data:    v.data,
-		Grad:    v.grad,
+		Data:    v.data.Decimal(),
+		Grad:    v.grad.Decimal(),
 		Operand: v.operation.String(),
 		ID:      v.ID(),
 		Label:   v.Label(),
 		Kind:    kind,
 		Layer:   layer,
-	}
+	}, nil
 }
 
 func deepCopy(n *graph.Node) *graph.Node {
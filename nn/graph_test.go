@@ -1,9 +1,13 @@
 package nn
 
 import (
+	"bytes"
+	"grad2go/graph"
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildGraphVizLayersString(t *testing.T) {
@@ -43,3 +47,93 @@ func TestBuildGraphVizLayersString(t *testing.T) {
 		})
 	}
 }
+
+type recordingGrapher struct {
+	nodes []*graph.Node
+	edges []*graph.Edge
+}
+
+func (r *recordingGrapher) ResetGraph() error {
+	r.nodes, r.edges = nil, nil
+	return nil
+}
+
+func (r *recordingGrapher) Render() (*bytes.Buffer, error) { return &bytes.Buffer{}, nil }
+
+func (r *recordingGrapher) AddNode(n *graph.Node) error {
+	r.nodes = append(r.nodes, n)
+	return nil
+}
+
+func (r *recordingGrapher) AddEdge(n, m *graph.Node, e *graph.Edge) error {
+	r.edges = append(r.edges, e)
+	return nil
+}
+
+func TestBuildGraphFromRootValueUnaryOperators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		op      func(v *Value) *Value
+		operand string
+	}{
+		{name: "relu", op: (*Value).ReLu, operand: "ReLu"},
+		{name: "tanh", op: (*Value).Tanh, operand: "Tanh"},
+		{name: "sigmoid", op: (*Value).Sigmoid, operand: "Sigmoid"},
+		{name: "exp", op: (*Value).Exp, operand: "Exp"},
+		{name: "log", op: (*Value).Log, operand: "Log"},
+		{name: "sqrt", op: (*Value).Sqrt, operand: "Sqrt"},
+		{name: "neg", op: (*Value).Neg, operand: "Neg"},
+		{name: "abs", op: (*Value).Abs, operand: "Abs"},
+		{name: "leaky_relu", op: func(v *Value) *Value { return v.LeakyReLu(0.01) }, operand: "LeakyReLu"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			x := NewValue(decimal.NewFromFloat(0.5), OperationNOOP, KindInput, "x")
+			root := tt.op(x)
+
+			g := &recordingGrapher{}
+			require.NoError(t, BuildGraphFromRootValue(g, root))
+
+			var operators []*graph.Node
+			for _, n := range g.nodes {
+				if n.Kind == graph.NodeKindOperator {
+					operators = append(operators, n)
+				}
+			}
+
+			require.Len(t, operators, 1)
+			assert.Equal(t, tt.operand, operators[0].Operand)
+			assert.Len(t, g.nodes, 3)
+			assert.Len(t, g.edges, 2)
+		})
+	}
+}
+
+func TestBuildGraphFromRootValueNonFinite(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []Backend{Float64Backend, DecimalBackend} {
+		backend := backend
+
+		t.Run(backend.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			x := NewValueFromScalar(backend.FromFloat64(1000), OperationNOOP, KindInput, "x")
+			root := x.Exp()
+			root.Backward()
+
+			g := &recordingGrapher{}
+
+			var err error
+			require.NotPanics(t, func() { err = BuildGraphFromRootValue(g, root) })
+			assert.ErrorIs(t, err, ErrNonFiniteValue)
+		})
+	}
+}
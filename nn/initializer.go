@@ -0,0 +1,80 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Initializer draws the initial data of a single parameter belonging to a layer with fanIn inputs & fanOut
+// outputs.
+type Initializer func(r *rand.Rand, fanIn, fanOut int) float64
+
+// defaultInitializer matches the historical behaviour of drawing parameters uniformly from [-1, 1].
+var defaultInitializer = UniformInitializer(-1, 1)
+
+// UniformInitializer draws parameters uniformly from [low, high).
+func UniformInitializer(low, high float64) Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		return low + r.Float64()*(high-low)
+	}
+}
+
+// NormalInitializer draws parameters from a normal distribution.
+func NormalInitializer(mean, stdDev float64) Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		return mean + r.NormFloat64()*stdDev
+	}
+}
+
+// XavierUniformInitializer (Glorot) draws parameters uniformly from [-a, a] with a = sqrt(6 / (fanIn + fanOut));
+// suited to tanh & sigmoid layers.
+func XavierUniformInitializer() Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		limit := math.Sqrt(6 / float64(maxInt(fanIn+fanOut, 1)))
+		return UniformInitializer(-limit, limit)(r, fanIn, fanOut)
+	}
+}
+
+// XavierNormalInitializer (Glorot) draws parameters from N(0, 2 / (fanIn + fanOut)).
+func XavierNormalInitializer() Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		stdDev := math.Sqrt(2 / float64(maxInt(fanIn+fanOut, 1)))
+		return NormalInitializer(0, stdDev)(r, fanIn, fanOut)
+	}
+}
+
+// HeUniformInitializer (Kaiming) draws parameters uniformly from [-a, a] with a = sqrt(6 / fanIn); suited to
+// ReLu layers.
+func HeUniformInitializer() Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		limit := math.Sqrt(6 / float64(maxInt(fanIn, 1)))
+		return UniformInitializer(-limit, limit)(r, fanIn, fanOut)
+	}
+}
+
+// HeNormalInitializer (Kaiming) draws parameters from N(0, 2 / fanIn).
+func HeNormalInitializer() Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		stdDev := math.Sqrt(2 / float64(maxInt(fanIn, 1)))
+		return NormalInitializer(0, stdDev)(r, fanIn, fanOut)
+	}
+}
+
+func ZerosInitializer() Initializer {
+	return ConstantInitializer(0)
+}
+
+func ConstantInitializer(c float64) Initializer {
+	return func(r *rand.Rand, fanIn, fanOut int) float64 {
+		return c
+	}
+}
+
+// resolveInitializer returns the initializer for the layer at index, falling back to the default.
+func resolveInitializer(initializers []Initializer, index int) Initializer {
+	if index < len(initializers) && initializers[index] != nil {
+		return initializers[index]
+	}
+
+	return defaultInitializer
+}
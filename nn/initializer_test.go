@@ -0,0 +1,133 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMLPFromConfigSeed(t *testing.T) {
+	t.Parallel()
+
+	build := func(seed int64) []float64 {
+		mlp := NewMLPFromConfig(NeuralNetworkConfig{
+			InputShape: 3,
+			Shape:      []int{4, 4, 2},
+			Seed:       seed,
+		})
+
+		var out []float64
+		for _, p := range mlp.Parameters() {
+			out = append(out, p.Float64())
+		}
+
+		return out
+	}
+
+	assert.Equal(t, build(42), build(42))
+	assert.NotEqual(t, build(42), build(43))
+}
+
+func TestInitializers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fanIn   = 8
+		fanOut  = 4
+		samples = 20_000
+	)
+
+	tests := []struct {
+		name           string
+		initializer    Initializer
+		limit          float64
+		expectedStdDev float64
+	}{
+		{
+			name:           "uniform",
+			initializer:    UniformInitializer(-0.5, 0.5),
+			limit:          0.5,
+			expectedStdDev: 1 / math.Sqrt(12),
+		},
+		{
+			name:           "normal",
+			initializer:    NormalInitializer(0, 0.2),
+			expectedStdDev: 0.2,
+		},
+		{
+			name:           "xavier_uniform",
+			initializer:    XavierUniformInitializer(),
+			limit:          math.Sqrt(6.0 / (fanIn + fanOut)),
+			expectedStdDev: math.Sqrt(2.0 / (fanIn + fanOut)),
+		},
+		{
+			name:           "xavier_normal",
+			initializer:    XavierNormalInitializer(),
+			expectedStdDev: math.Sqrt(2.0 / (fanIn + fanOut)),
+		},
+		{
+			name:           "he_uniform",
+			initializer:    HeUniformInitializer(),
+			limit:          math.Sqrt(6.0 / fanIn),
+			expectedStdDev: math.Sqrt(2.0 / fanIn),
+		},
+		{
+			name:           "he_normal",
+			initializer:    HeNormalInitializer(),
+			expectedStdDev: math.Sqrt(2.0 / fanIn),
+		},
+		{
+			name:        "zeros",
+			initializer: ZerosInitializer(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := rand.New(rand.NewSource(1))
+
+			var sum, sumOfSquares float64
+			for i := 0; i < samples; i++ {
+				x := tt.initializer(r, fanIn, fanOut)
+				if tt.limit > 0 {
+					require.LessOrEqual(t, math.Abs(x), tt.limit)
+				}
+
+				sum += x
+				sumOfSquares += x * x
+			}
+
+			mean := sum / samples
+			stdDev := math.Sqrt(sumOfSquares/samples - mean*mean)
+
+			assert.InDelta(t, 0, mean, 0.02)
+			assert.InDelta(t, tt.expectedStdDev, stdDev, 0.02)
+		})
+	}
+}
+
+func TestConstantInitializer(t *testing.T) {
+	t.Parallel()
+
+	mlp := NewMLPFromConfig(NeuralNetworkConfig{
+		InputShape:   2,
+		Shape:        []int{3, 1},
+		Initializers: []Initializer{ConstantInitializer(0.5)},
+	})
+
+	// Only the first layer is overridden; the second falls back to the default.
+	for _, p := range mlp.layers[0].Parameters() {
+		assert.Equal(t, 0.5, p.Float64())
+	}
+
+	for _, p := range mlp.layers[1].Parameters() {
+		assert.LessOrEqual(t, math.Abs(p.Float64()), 1.0)
+	}
+}
@@ -1,47 +1,101 @@
 package nn
 
-import "strconv"
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
 
+// NewLayer creates a fully connected layer of numberOfOutputs neurons, each taking numberOfInputs inputs.
 func NewLayer(numberOfInputs, numberOfOutputs int) *Layer {
-	var neurons = make([]*Neuron, 0, numberOfInputs)
-	for i := 0; i < numberOfInputs; i++ {
-		neurons = append(neurons, NewNeuron(numberOfOutputs))
+	var neurons = make([]*Neuron, 0, numberOfOutputs)
+	for i := 0; i < numberOfOutputs; i++ {
+		neurons = append(neurons, NewNeuron(numberOfInputs))
 	}
 
 	return &Layer{
-		neurons: neurons,
+		neurons:    neurons,
+		activation: ActivationReLu,
 	}
 }
 
 // TODO: pass context & not label.
 func NewLayerWithLabel(numberOfInputs, numberOfOutputs int, id int) *Layer {
-	var neurons = make([]*Neuron, 0, numberOfInputs)
-	for i := 0; i < numberOfInputs; i++ {
+	return newLayer(layerConfig{
+		id:              id,
+		numberOfInputs:  numberOfInputs,
+		numberOfOutputs: numberOfOutputs,
+		backend:         DefaultBackend,
+		activation:      ActivationReLu,
+		initializer:     defaultInitializer,
+		r:               rand.New(rand.NewSource(time.Now().UnixNano())),
+	})
+}
+
+type layerConfig struct {
+	id              int
+	numberOfInputs  int
+	numberOfOutputs int
+	backend         Backend
+	activation      Activation
+	initializer     Initializer
+	// r is shared by every neuron of the layer, so that a single seed determines the whole initialization.
+	r *rand.Rand
+}
+
+func newLayer(cfg layerConfig) *Layer {
+	var neurons = make([]*Neuron, 0, cfg.numberOfOutputs)
+	for i := 0; i < cfg.numberOfOutputs; i++ {
 		context := &context{
-			Layer:  id,
+			Layer:  cfg.id,
 			Neuron: strconv.Itoa(i),
 		}
-		neurons = append(neurons, NewNeuronWithContext(numberOfOutputs, context))
+		neurons = append(neurons, newNeuron(neuronConfig{
+			numberOfInputs:  cfg.numberOfInputs,
+			numberOfOutputs: cfg.numberOfOutputs,
+			context:         context,
+			backend:         cfg.backend,
+			activation:      cfg.activation,
+			initializer:     cfg.initializer,
+			r:               cfg.r,
+		}))
 	}
 
 	return &Layer{
-		neurons: neurons,
-		id:      id,
+		neurons:    neurons,
+		id:         cfg.id,
+		activation: cfg.activation,
 	}
 }
 
 type Layer struct {
-	neurons []*Neuron
-	id      int
+	neurons    []*Neuron
+	id         int
+	activation Activation
+}
+
+func (l *Layer) Forward(inputs []*Value) ([]*Value, error) {
+	return l.forward(inputs, false)
 }
 
-func (l *Layer) Forward(inputs []*Value) []*Value {
+func (l *Layer) forward(inputs []*Value, noGrad bool) ([]*Value, error) {
 	var out = make([]*Value, 0, len(l.neurons))
-	for _, n := range l.neurons {
-		out = append(out, n.Forward(inputs))
+	for i, n := range l.neurons {
+		activation, err := n.forward(inputs, noGrad)
+		if err != nil {
+			return nil, fmt.Errorf("neuron %d: %w", i, err)
+		}
+
+		out = append(out, activation)
 	}
 
-	return out
+	// Softmax depends on every output of the layer, so is applied here rather than by each neuron.
+	if l.activation == ActivationSoftmax {
+		return Softmax(out)
+	}
+
+	return out, nil
 }
 
 func (l *Layer) Parameters() []*Value {
@@ -53,3 +107,21 @@ func (l *Layer) Parameters() []*Value {
 
 	return out
 }
+
+// NamedParameters returns the parameters of the layer in the same order as Parameters, each prefixed by the
+// index of its neuron, e.g neuron.1.weight.0.
+func (l *Layer) NamedParameters() []NamedParameter {
+	var out = make([]NamedParameter, 0)
+
+	for i, n := range l.neurons {
+		out = append(out, prefixNamedParameters(fmt.Sprintf("neuron.%d", i), n.NamedParameters())...)
+	}
+
+	return out
+}
+
+func (l *Layer) ZeroGrad() {
+	for _, n := range l.neurons {
+		n.ZeroGrad()
+	}
+}
@@ -1 +1,32 @@
 package nn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLayerShape(t *testing.T) {
+	t.Parallel()
+
+	l := NewLayer(3, 5)
+
+	out, err := l.Forward(newTestInputs(1, 2, 3))
+	require.NoError(t, err)
+	assert.Len(t, out, 5)
+	assert.Len(t, l.Parameters(), 3*5+5)
+}
+
+// A layer must build one neuron per output, each taking every input; with a non-square shape swapping the two
+// changes both the number of outputs & the number of parameters.
+func TestNewMLPNonSquareShape(t *testing.T) {
+	t.Parallel()
+
+	m := NewMLP(3, []int{5, 2})
+
+	out, err := m.Forward(newTestInputs(1, 2, 3))
+	require.NoError(t, err)
+	assert.Len(t, out, 2)
+	assert.Len(t, m.Parameters(), 3*5+5+5*2+2)
+}
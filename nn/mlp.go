@@ -1,13 +1,47 @@
 package nn
 
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
 func NewMLP(numberOfInputs int, outputSizes []int) *MLP {
-	var sizes = make([]int, 0, 1+len(outputSizes))
-	sizes = append(sizes, numberOfInputs)
-	sizes = append(sizes, outputSizes...)
+	return NewMLPFromConfig(NeuralNetworkConfig{
+		InputShape: numberOfInputs,
+		Shape:      outputSizes,
+	})
+}
+
+// NewMLPFromConfig builds an MLP with the shape, activations, initializers & numeric backend described by the
+// config.
+func NewMLPFromConfig(cfg NeuralNetworkConfig) *MLP {
+	var sizes = make([]int, 0, 1+len(cfg.Shape))
+	sizes = append(sizes, cfg.InputShape)
+	sizes = append(sizes, cfg.Shape...)
+
+	var backend = cfg.Backend
+	if backend == nil {
+		backend = DefaultBackend
+	}
 
-	var layers = make([]*Layer, 0, len(outputSizes))
-	for i := 0; i < len(outputSizes); i++ {
-		layers = append(layers, NewLayerWithLabel(sizes[i], sizes[i+1], i))
+	var seed = cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	var layers = make([]*Layer, 0, len(cfg.Shape))
+	for i := 0; i < len(cfg.Shape); i++ {
+		layers = append(layers, newLayer(layerConfig{
+			id:              i,
+			numberOfInputs:  sizes[i],
+			numberOfOutputs: sizes[i+1],
+			backend:         backend,
+			activation:      resolveActivation(cfg.Activations, i, len(cfg.Shape)),
+			initializer:     resolveInitializer(cfg.Initializers, i),
+			r:               r,
+		}))
 	}
 
 	return &MLP{
@@ -19,13 +53,23 @@ type MLP struct {
 	layers []*Layer
 }
 
-func (m *MLP) Forward(inputs []*Value) []*Value {
+func (m *MLP) Forward(inputs []*Value) ([]*Value, error) {
+	return m.forward(inputs, false)
+}
+
+// forward runs the inputs through every layer; if noGrad is set the layers compute on no-grad views of the
+// parameters, which is used for inference.
+func (m *MLP) forward(inputs []*Value, noGrad bool) ([]*Value, error) {
 	var out = inputs
-	for _, l := range m.layers {
-		out = l.Forward(out)
+	for i, l := range m.layers {
+		var err error
+		out, err = l.forward(out, noGrad)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i, err)
+		}
 	}
 
-	return out
+	return out, nil
 }
 
 func (m *MLP) Parameters() []*Value {
@@ -36,3 +80,21 @@ func (m *MLP) Parameters() []*Value {
 
 	return out
 }
+
+// NamedParameters returns the parameters of the MLP in the same order as Parameters, each prefixed by the index
+// of its layer, e.g layer.0.neuron.1.weight.0. Names only depend on the shape of the MLP, so they are stable
+// across processes & can be used to checkpoint state keyed by parameter.
+func (m *MLP) NamedParameters() []NamedParameter {
+	var out = make([]NamedParameter, 0, len(m.layers))
+	for i, l := range m.layers {
+		out = append(out, prefixNamedParameters(fmt.Sprintf("layer.%d", i), l.NamedParameters())...)
+	}
+
+	return out
+}
+
+func (m *MLP) ZeroGrad() {
+	for _, l := range m.layers {
+		l.ZeroGrad()
+	}
+}
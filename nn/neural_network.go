@@ -3,11 +3,15 @@ package nn
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 )
 
 var (
 	ErrInvalidNeuralNetworkPhase = errors.New("invalid neural network phase")
+	ErrShapeMismatch             = errors.New("shape mismatch")
+	ErrOptimizerStateUnsupported = errors.New("optimizer does not support state serialization")
+	ErrInvalidSampleWeight       = errors.New("invalid sample weight")
 )
 
 type Phase int8
@@ -35,7 +39,7 @@ func (n Phase) String() string {
 }
 
 func NewNeuralNetwork(cfg NeuralNetworkConfig, optimizer Optimizer, losser Losser) *NeuralNetwork {
-	mlp := NewMLP(cfg.InputShape, cfg.Shape)
+	mlp := NewMLPFromConfig(cfg)
 
 	return &NeuralNetwork{
 		Optimizer: optimizer,
@@ -49,24 +53,125 @@ func NewNeuralNetwork(cfg NeuralNetworkConfig, optimizer Optimizer, losser Losse
 type NeuralNetworkConfig struct {
 	InputShape int
 	Shape      []int
+	// AccumulationSteps is the number of steps whose gradients are summed before the optimizer updates the
+	// parameters; the steps in between only run the backward pass. The gradients are reset before the first step of
+	// each accumulation, so scale the loss by 1 / AccumulationSteps to step on their mean. Zero or one updates the
+	// parameters on every step.
+	AccumulationSteps int
+	// Backend is the numeric backend the parameters are held in; defaults to DefaultBackend.
+	Backend Backend
+	// Activations is the activation of each layer, indexed as Shape. Missing or ActivationDefault entries resolve
+	// to ReLu for hidden layers & Linear for the output layer.
+	Activations []Activation
+	// Initializers is the parameter initializer of each layer, indexed as Shape. Missing or nil entries resolve to
+	// a uniform draw from [-1, 1].
+	Initializers []Initializer
+	// Seed seeds the single random source used to initialize every parameter of the network, making the
+	// initialization reproducible. A zero seed draws a seed from the current time.
+	Seed int64
 }
 
-type Optimizer func(input []*Value)
+// Optimizer updates the parameters of a network from their gradients.
+type Optimizer interface {
+	// Step applies a single update to the params from their current gradients.
+	Step(params []*Value)
+	ZeroGrad(params []*Value)
+	LearningRate() float64
+	// SetLearningRate sets the learning rate used by subsequent steps; a negative, infinite or NaN rate is ignored.
+	SetLearningRate(rate float64)
+}
+
+// OptimizerFunc adapts a plain function to the Optimizer interface. It has no learning rate of its own, so
+// LearningRate reports zero & SetLearningRate is a no-op.
+type OptimizerFunc func(params []*Value)
+
+func (f OptimizerFunc) Step(params []*Value) { f(params) }
+
+func (f OptimizerFunc) ZeroGrad(params []*Value) {
+	for _, p := range params {
+		p.ZeroGrad()
+	}
+}
+
+func (f OptimizerFunc) LearningRate() float64 { return 0 }
+
+func (f OptimizerFunc) SetLearningRate(rate float64) {}
+
+// OptimizerStateMarshaler is implemented by optimizers which carry state between steps, e.g velocities or moments,
+// so that the state can be checkpointed & restored. State is keyed by parameter name rather than by pointer, so it
+// can be restored onto a freshly built network of the same shape.
+type OptimizerStateMarshaler interface {
+	MarshalState(params []NamedParameter) ([]byte, error)
+	UnmarshalState(data []byte, params []NamedParameter) error
+}
+
+// NamedParameter is a parameter together with its stable name within the network.
+type NamedParameter struct {
+	Name  string
+	Value *Value
+}
+
+func prefixNamedParameters(prefix string, params []NamedParameter) []NamedParameter {
+	for i := range params {
+		params[i].Name = prefix + "." + params[i].Name
+	}
+
+	return params
+}
 
 type Losser func(output, expectation []*Value) (*Value, error)
 
+// Sample holds the arguments of a step which belong to the sample being trained on rather than to the network.
+type Sample struct {
+	// Weight scales the loss of the sample, e.g to up weight the samples of a rare class; it must be finite & not
+	// negative, & a zero weight leaves the sample out of the update.
+	Weight float64
+	// Label is a label of the sample which isn't part of the expectation, e.g whether a pair of embeddings is
+	// similar; it is only read by a SampleLosser.
+	Label float64
+}
+
+// SampleLosser is a Losser which also receives the sample of the step, e.g to read its label. The network scales
+// the loss by the weight of the sample, so a SampleLosser must not apply the weight itself.
+type SampleLosser func(output, expectation []*Value, sample Sample) (*Value, error)
+
+// Regularizer builds a penalty over the parameters of a network, which is added to the loss so that it is part of
+// the graph.
+type Regularizer func(params []*Value) (*Value, error)
+
 type NeuralNetwork struct {
-	Optimizer     Optimizer
-	Losser        Losser
-	cfg           NeuralNetworkConfig
-	mlp           *MLP
-	phase         Phase
-	phaseMu       sync.RWMutex
-	outputStore   []*Value
-	outputStoreMu sync.RWMutex
+	Optimizer Optimizer
+	Losser    Losser
+	// SampleLosser, if set, is used instead of Losser, so that the loss can read the sample of each step.
+	SampleLosser SampleLosser
+	// Regularizer, if set, adds a penalty over the parameters to the loss of every step.
+	Regularizer Regularizer
+	// GradientClipper, if set, clips the parameter gradients after the backward pass & before the optimizer step.
+	GradientClipper GradientClipper
+	cfg             NeuralNetworkConfig
+	mlp             *MLP
+	phase           Phase
+	phaseMu         sync.RWMutex
+	outputStore     []*Value
+	outputStoreMu   sync.RWMutex
+	gradientNorm    float64
+	gradientNormMu  sync.RWMutex
+	// accumulated is the number of backward passes whose gradients haven't been applied by the optimizer yet.
+	accumulated int
 }
 
+// Step trains the network on a single sample of unit weight.
 func (n *NeuralNetwork) Step(input, expectation []*Value) (*Value, error) {
+	return n.StepSample(input, expectation, Sample{Weight: 1})
+}
+
+// StepSample trains the network on a single sample, whose loss is scaled by the weight of the sample before the
+// penalty of the Regularizer is added.
+func (n *NeuralNetwork) StepSample(input, expectation []*Value, sample Sample) (*Value, error) {
+	if !(sample.Weight >= 0) || math.IsInf(sample.Weight, 1) {
+		return nil, fmt.Errorf("weight %f must be finite & not negative: %w", sample.Weight, ErrInvalidSampleWeight)
+	}
+
 	if err := n.forward(input); err != nil {
 		return nil, fmt.Errorf("forward step failed: %w", err)
 	}
@@ -76,11 +181,27 @@ func (n *NeuralNetwork) Step(input, expectation []*Value) (*Value, error) {
 	n.outputStoreMu.RUnlock()
 
 	// TODO: we can check shape beforehand as this is the likely cause of error.
-	loss, err := n.Losser(output, expectation)
+	loss, err := n.loss(output, expectation, sample)
 	if err != nil {
+		n.setPhase(PhaseStatic)
 		return nil, fmt.Errorf("failed to perform loss function: %w", err)
 	}
 
+	if sample.Weight != 1 {
+		weight := loss.Backend().FromFloat64(sample.Weight)
+		loss = loss.Mul(NewValueFromScalar(weight, OperationNOOP, KindValue, "sample_weight"))
+	}
+
+	if n.Regularizer != nil {
+		penalty, err := n.Regularizer(n.mlp.Parameters())
+		if err != nil {
+			n.setPhase(PhaseStatic)
+			return nil, fmt.Errorf("failed to perform regularizer: %w", err)
+		}
+
+		loss = loss.Add(penalty)
+	}
+
 	if err := n.backpropagation(loss); err != nil {
 		return nil, fmt.Errorf("backpropagation step failed: %w", err)
 	}
@@ -94,6 +215,40 @@ func (n *NeuralNetwork) Step(input, expectation []*Value) (*Value, error) {
 	return loss, nil
 }
 
+func (n *NeuralNetwork) loss(output, expectation []*Value, sample Sample) (*Value, error) {
+	if n.SampleLosser != nil {
+		return n.SampleLosser(output, expectation, sample)
+	}
+
+	return n.Losser(output, expectation)
+}
+
+// Predict runs the inputs forward through the network for inference only. The inputs & parameters are viewed in
+// no-grad mode, so no backward graph is built; neither the phase nor the parameter gradients are touched.
+func (n *NeuralNetwork) Predict(inputs []*Value) ([]*Value, error) {
+	var noGradInputs = make([]*Value, len(inputs))
+	for i, input := range inputs {
+		noGradInputs[i] = input.NoGrad()
+	}
+
+	output, err := n.mlp.forward(noGradInputs, true)
+	if err != nil {
+		return nil, fmt.Errorf("predict failed: %w", err)
+	}
+
+	return output, nil
+}
+
+// GradientNorm returns the global L2 norm of the parameter gradients before clipping, as reported by the
+// GradientClipper on the last step, e.g to monitor how often & how hard clipping kicks in. It is zero if no step
+// has been clipped.
+func (n *NeuralNetwork) GradientNorm() float64 {
+	n.gradientNormMu.RLock()
+	defer n.gradientNormMu.RUnlock()
+
+	return n.gradientNorm
+}
+
 func (n *NeuralNetwork) Phase() Phase {
 	n.phaseMu.RLock()
 	defer n.phaseMu.RUnlock()
@@ -123,6 +278,43 @@ func (n *NeuralNetwork) Layers() int {
 
 func (n *NeuralNetwork) HiddenLayers() int { return n.Layers() - 1 }
 
+// Parameters returns every parameter of the network.
+func (n *NeuralNetwork) Parameters() []*Value {
+	return n.mlp.Parameters()
+}
+
+// NamedParameters returns every parameter of the network together with its stable name.
+func (n *NeuralNetwork) NamedParameters() []NamedParameter {
+	return n.mlp.NamedParameters()
+}
+
+// MarshalOptimizerState serializes the state of the optimizer against the parameter names of the network; it
+// returns ErrOptimizerStateUnsupported if the optimizer doesn't implement OptimizerStateMarshaler.
+func (n *NeuralNetwork) MarshalOptimizerState() ([]byte, error) {
+	marshaler, ok := n.Optimizer.(OptimizerStateMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("marshal optimizer state: %w", ErrOptimizerStateUnsupported)
+	}
+
+	return marshaler.MarshalState(n.mlp.NamedParameters())
+}
+
+// UnmarshalOptimizerState restores optimizer state previously produced by MarshalOptimizerState onto the
+// parameters of the network; the network must have the same shape as the one the state was taken from.
+func (n *NeuralNetwork) UnmarshalOptimizerState(data []byte) error {
+	marshaler, ok := n.Optimizer.(OptimizerStateMarshaler)
+	if !ok {
+		return fmt.Errorf("unmarshal optimizer state: %w", ErrOptimizerStateUnsupported)
+	}
+
+	return marshaler.UnmarshalState(data, n.mlp.NamedParameters())
+}
+
+// ZeroGrad resets the gradients of all parameters of the network.
+func (n *NeuralNetwork) ZeroGrad() {
+	n.mlp.ZeroGrad()
+}
+
 func (n *NeuralNetwork) setPhase(newPhase Phase) {
 	n.phaseMu.Lock()
 	defer n.phaseMu.Unlock()
@@ -141,9 +333,16 @@ func (n *NeuralNetwork) forward(inputs []*Value) error {
 	}
 	n.setPhase(PhaseForward)
 
+	output, err := n.mlp.Forward(inputs)
+	if err != nil {
+		// Nothing has been computed, so the network can safely be returned to static for the next step.
+		n.setPhase(PhaseStatic)
+		return err
+	}
+
 	n.outputStoreMu.Lock()
 	defer n.outputStoreMu.Unlock()
-	n.outputStore = n.mlp.Forward(inputs)
+	n.outputStore = output
 
 	return nil
 }
@@ -158,7 +357,12 @@ func (n *NeuralNetwork) backpropagation(lossValue *Value) error {
 	}
 	n.setPhase(PhaseBackward)
 
+	if n.accumulated == 0 {
+		n.mlp.ZeroGrad()
+	}
+
 	lossValue.Backward()
+	n.accumulated++
 
 	return nil
 }
@@ -173,8 +377,22 @@ func (n *NeuralNetwork) optimize() error {
 	}
 	n.setPhase(PhaseOptimize)
 
+	// The gradients are kept for the next backward pass until enough steps have been accumulated.
+	if n.accumulated < n.cfg.AccumulationSteps {
+		return nil
+	}
+	n.accumulated = 0
+
 	params := n.mlp.Parameters()
-	n.Optimizer(params)
+	if n.GradientClipper != nil {
+		norm := n.GradientClipper(params)
+
+		n.gradientNormMu.Lock()
+		n.gradientNorm = norm
+		n.gradientNormMu.Unlock()
+	}
+
+	n.Optimizer.Step(params)
 
 	return nil
 }
@@ -0,0 +1,281 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInputs(xs ...float64) []*Value {
+	var out = make([]*Value, len(xs))
+	for i, x := range xs {
+		out[i] = NewValue(decimal.NewFromFloat(x), OperationNOOP, KindInput, "")
+	}
+
+	return out
+}
+
+// sumLosser is a minimal losser that sums the outputs, so that the gradient of every output is one.
+func sumLosser(output, expectation []*Value) (*Value, error) {
+	sum := output[0]
+	for _, o := range output[1:] {
+		sum = sum.Add(o)
+	}
+
+	return sum, nil
+}
+
+func TestNeuralNetworkStepZeroGrad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		accumulationSteps  int
+		expectedMultiplier float64
+		expectedUpdates    int
+	}{
+		{
+			name:               "reset",
+			expectedMultiplier: 1,
+			expectedUpdates:    2,
+		},
+		{
+			name:               "accumulate",
+			accumulationSteps:  2,
+			expectedMultiplier: 2,
+			expectedUpdates:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var updates int
+			net := NewNeuralNetwork(NeuralNetworkConfig{
+				InputShape:        2,
+				Shape:             []int{2, 2},
+				AccumulationSteps: tt.accumulationSteps,
+			}, OptimizerFunc(func(params []*Value) { updates++ }), sumLosser)
+
+			inputs := newTestInputs(0.5, -0.25)
+
+			_, err := net.Step(inputs, nil)
+			require.NoError(t, err)
+
+			var first []float64
+			for _, p := range net.mlp.Parameters() {
+				first = append(first, p.Grad())
+			}
+
+			// With a no-op optimizer the parameters are unchanged, so the second step yields the same gradients.
+			_, err = net.Step(inputs, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedUpdates, updates)
+			for i, p := range net.mlp.Parameters() {
+				assert.InDelta(t, tt.expectedMultiplier*first[i], p.Grad(), 1e-9)
+			}
+
+			// The gradients of a completed accumulation are reset by the next step.
+			_, err = net.Step(inputs, nil)
+			require.NoError(t, err)
+
+			for i, p := range net.mlp.Parameters() {
+				assert.InDelta(t, first[i], p.Grad(), 1e-9)
+			}
+
+			net.ZeroGrad()
+			for _, p := range net.mlp.Parameters() {
+				assert.Zero(t, p.Grad())
+			}
+		})
+	}
+}
+
+func TestNeuralNetworkStepSample(t *testing.T) {
+	t.Parallel()
+
+	newNetwork := func() *NeuralNetwork {
+		return NewNeuralNetwork(NeuralNetworkConfig{
+			InputShape: 2,
+			Shape:      []int{2, 2},
+			Seed:       1,
+		}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+	}
+
+	inputs := newTestInputs(0.5, -0.25)
+
+	unit := newNetwork()
+	unitLoss, err := unit.Step(inputs, nil)
+	require.NoError(t, err)
+
+	weighted := newNetwork()
+	weightedLoss, err := weighted.StepSample(inputs, nil, Sample{Weight: 3})
+	require.NoError(t, err)
+
+	// The weight scales the loss & so every gradient.
+	assert.InDelta(t, 3*unitLoss.Float64(), weightedLoss.Float64(), 1e-9)
+
+	expected := unit.Parameters()
+	for i, p := range weighted.Parameters() {
+		assert.InDelta(t, 3*expected[i].Grad(), p.Grad(), 1e-9)
+	}
+
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+		_, err := weighted.StepSample(inputs, nil, Sample{Weight: weight})
+		assert.ErrorIs(t, err, ErrInvalidSampleWeight)
+		assert.Equal(t, PhaseStatic, weighted.Phase())
+	}
+}
+
+func TestNeuralNetworkSampleLosser(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{2, 1},
+		Seed:       1,
+	}, OptimizerFunc(func(params []*Value) {}), nil)
+
+	// The sample losser is used instead of the losser & reads the label of each step.
+	net.SampleLosser = func(output, expectation []*Value, sample Sample) (*Value, error) {
+		label := NewValueFromScalar(output[0].Backend().FromFloat64(sample.Label), OperationNOOP, KindValue, "label")
+		return output[0].Mul(label), nil
+	}
+
+	inputs := newTestInputs(0.5, -0.25)
+
+	positive, err := net.StepSample(inputs, nil, Sample{Weight: 1, Label: 1})
+	require.NoError(t, err)
+
+	negative, err := net.StepSample(inputs, nil, Sample{Weight: 2, Label: -1})
+	require.NoError(t, err)
+
+	assert.InDelta(t, -2*positive.Float64(), negative.Float64(), 1e-9)
+}
+
+func TestNeuralNetworkStepShapeMismatch(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{3, 1},
+	}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+
+	_, err := net.Step(newTestInputs(0.5, -0.25, 1.0), nil)
+	assert.ErrorIs(t, err, ErrShapeMismatch)
+
+	// A failed step must leave the network ready for the next one.
+	assert.Equal(t, PhaseStatic, net.Phase())
+
+	_, err = net.Step(newTestInputs(0.5, -0.25), nil)
+	assert.NoError(t, err)
+}
+
+func TestNeuralNetworkPredict(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{3, 2},
+	}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+
+	inputs := newTestInputs(0.5, -0.25)
+
+	expected, err := net.mlp.Forward(inputs)
+	require.NoError(t, err)
+
+	output, err := net.Predict(inputs)
+	require.NoError(t, err)
+	require.Len(t, output, 2)
+
+	for i, o := range output {
+		assert.Equal(t, expected[i].Float64(), o.Float64())
+		assert.False(t, o.RequiresGrad())
+		assert.Len(t, o.Topo(), 1)
+	}
+
+	assert.Equal(t, PhaseStatic, net.Phase())
+	for _, p := range net.mlp.Parameters() {
+		assert.Zero(t, p.Grad())
+	}
+
+	_, err = net.Predict(newTestInputs(0.5))
+	assert.ErrorIs(t, err, ErrShapeMismatch)
+}
+
+func TestNeuralNetworkNamedParameters(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{3, 1},
+	}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+
+	params := net.Parameters()
+	named := net.NamedParameters()
+	require.Len(t, named, len(params))
+
+	var seen = make(map[string]struct{}, len(named))
+	for i, p := range named {
+		assert.Same(t, params[i], p.Value)
+
+		_, ok := seen[p.Name]
+		assert.False(t, ok, "duplicate name %s", p.Name)
+		seen[p.Name] = struct{}{}
+	}
+
+	assert.Equal(t, "layer.0.neuron.0.weight.0", named[0].Name)
+	assert.Equal(t, "layer.0.neuron.0.bias.0", named[2].Name)
+	assert.Equal(t, "layer.1.neuron.0.bias.0", named[len(named)-1].Name)
+}
+
+func TestNeuralNetworkOptimizerStateUnsupported(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{1},
+	}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+
+	_, err := net.MarshalOptimizerState()
+	assert.ErrorIs(t, err, ErrOptimizerStateUnsupported)
+
+	err = net.UnmarshalOptimizerState([]byte("{}"))
+	assert.ErrorIs(t, err, ErrOptimizerStateUnsupported)
+}
+
+func TestNeuralNetworkRegularizer(t *testing.T) {
+	t.Parallel()
+
+	net := NewNeuralNetwork(NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      []int{1},
+		Seed:       1,
+	}, OptimizerFunc(func(params []*Value) {}), sumLosser)
+
+	inputs := newTestInputs(0.5, -0.5)
+	output, err := net.Predict(inputs)
+	require.NoError(t, err)
+
+	const penalty = 10.0
+	net.Regularizer = func(params []*Value) (*Value, error) {
+		return NewValue(decimal.NewFromFloat(penalty), OperationNOOP, KindValue, "penalty"), nil
+	}
+
+	loss, err := net.Step(inputs, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, output[0].Float64()+penalty, loss.Float64(), 1e-12)
+
+	net.Regularizer = func(params []*Value) (*Value, error) { return nil, ErrShapeMismatch }
+
+	_, err = net.Step(inputs, nil)
+	assert.ErrorIs(t, err, ErrShapeMismatch)
+	assert.Equal(t, PhaseStatic, net.Phase())
+}
@@ -1,66 +1,98 @@
 package nn
 
 import (
-	"log"
+	"fmt"
 	"math/rand"
 	"time"
-
-	"github.com/shopspring/decimal"
 )
 
-var minusOne = decimal.NewFromFloat(-1.0)
-
 func NewNeuron(numberOfInputs int) *Neuron {
-	n := &Neuron{
-		r: rand.New(rand.NewSource(time.Now().UnixNano())),
-		d: numberOfInputs,
-	}
+	return NewNeuronWithContext(numberOfInputs, nil)
+}
 
-	n.W = n.generateRandomVector(numberOfInputs, 1, KindWeight)
-	n.B = n.generateRandomVector(1, 1, KindBias)
+func NewNeuronWithContext(numberOfInputs int, context *context) *Neuron {
+	return newNeuron(neuronConfig{
+		numberOfInputs:  numberOfInputs,
+		numberOfOutputs: 1,
+		context:         context,
+		backend:         DefaultBackend,
+		activation:      ActivationReLu,
+		initializer:     defaultInitializer,
+		r:               rand.New(rand.NewSource(time.Now().UnixNano())),
+	})
+}
 
-	return n
+type neuronConfig struct {
+	numberOfInputs int
+	// numberOfOutputs is the number of outputs of the layer the neuron belongs to, i.e the fan out.
+	numberOfOutputs int
+	context         *context
+	backend         Backend
+	activation      Activation
+	initializer     Initializer
+	r               *rand.Rand
 }
 
-func NewNeuronWithContext(numberOfInputs int, context *context) *Neuron {
-	// TODO: consolidate with the above.
+func newNeuron(cfg neuronConfig) *Neuron {
 	n := &Neuron{
-		r:       rand.New(rand.NewSource(time.Now().UnixNano())),
-		d:       numberOfInputs,
-		context: context,
+		r:           cfg.r,
+		d:           cfg.numberOfInputs,
+		fanOut:      cfg.numberOfOutputs,
+		context:     cfg.context,
+		backend:     cfg.backend,
+		activation:  cfg.activation,
+		initializer: cfg.initializer,
 	}
 
-	n.W = n.generateRandomVector(numberOfInputs, 1, KindWeight)
-	n.B = n.generateRandomVector(1, 1, KindBias)
+	n.W = n.initializeVector(cfg.numberOfInputs, KindWeight)
+	n.B = n.initializeVector(1, KindBias)
 
 	return n
 }
 
 type Neuron struct {
-	W       []*Value
-	B       []*Value
-	r       *rand.Rand
-	d       int
-	context *context
+	W           []*Value
+	B           []*Value
+	r           *rand.Rand
+	d           int
+	fanOut      int
+	context     *context
+	backend     Backend
+	activation  Activation
+	initializer Initializer
+}
+
+func (n *Neuron) Forward(inputs []*Value) (*Value, error) {
+	return n.forward(inputs, false)
 }
 
-func (n *Neuron) Forward(inputs []*Value) *Value {
+// forward computes the activation of the neuron; if noGrad is set it computes on no-grad views of the parameters,
+// so that no backward graph is built & the parameters can never receive a gradient.
+func (n *Neuron) forward(inputs []*Value, noGrad bool) (*Value, error) {
 	if len(inputs) != n.d {
-		log.Fatalf("invalid dim of inputs: got %d, expected %d", len(inputs), n.d)
+		return nil, fmt.Errorf("invalid dim of inputs: got %d, expected %d: %w", len(inputs), n.d, ErrShapeMismatch)
+	}
+
+	param := func(p *Value) *Value {
+		if noGrad {
+			return p.NoGrad()
+		}
+
+		return p
 	}
 
 	// w * x + b
-	var sum = n.B[0]
+	var sum = param(n.B[0])
 	for i := 0; i < n.d; i++ {
-		w := n.W[i]
+		w := param(n.W[i])
 		x := inputs[i]
 		product := w.Mul(x)
 
 		sum = sum.Add(product)
 	}
-	activation := sum.ReLu()
+	activation := n.activation.apply(sum)
 
-	return activation
+	return activation, nil
 }
 
 func (n *Neuron) Parameters() []*Value {
@@ -72,30 +104,32 @@ func (n *Neuron) Parameters() []*Value {
 	return out
 }
 
-func (n *Neuron) generateRandomVector(size int, linSpace float64, kind Kind) []*Value {
-	switch {
-	case linSpace == 0:
-		linSpace = 1
-	case linSpace < 0:
-		linSpace = -1.0 * linSpace
+// NamedParameters returns the parameters of the neuron in the same order as Parameters, each named by its kind &
+// index within the neuron, e.g weight.0 or bias.0.
+func (n *Neuron) NamedParameters() []NamedParameter {
+	var out = make([]NamedParameter, 0, len(n.W)+len(n.B))
+	for i, w := range n.W {
+		out = append(out, NamedParameter{Name: fmt.Sprintf("weight.%d", i), Value: w})
+	}
+	for i, b := range n.B {
+		out = append(out, NamedParameter{Name: fmt.Sprintf("bias.%d", i), Value: b})
 	}
 
-	var out = make([]*Value, size)
-
-	for i := 0; i < size; i++ {
-		negative := n.r.Float64() > 0.5
-		value := decimal.NewFromFloat(n.r.Float64())
+	return out
+}
 
-		if linSpace != 1 {
-			rangeMul := decimal.NewFromFloat(linSpace)
-			value = value.Mul(rangeMul)
-		}
+func (n *Neuron) ZeroGrad() {
+	for _, p := range n.Parameters() {
+		p.ZeroGrad()
+	}
+}
 
-		if negative {
-			value = value.Mul(minusOne)
-		}
+func (n *Neuron) initializeVector(size int, kind Kind) []*Value {
+	var out = make([]*Value, size)
 
-		out[i] = newValueWithContext(value, OperationNOOP, kind, n.context)
+	for i := 0; i < size; i++ {
+		value := n.initializer(n.r, n.d, n.fanOut)
+		out[i] = newValueWithContext(n.backend.FromFloat64(value), OperationNOOP, kind, n.context)
 	}
 
 	return out
@@ -1,17 +1,9 @@
 package nn
 
 import (
-	"github.com/shopspring/decimal"
+	"math"
 )
 
-func max(a, b decimal.Decimal) decimal.Decimal {
-	if a.GreaterThanOrEqual(b) {
-		return a
-	}
-
-	return b
-}
-
 func zip[T any](a, b []T, defaultValue T) [][]T {
 	var out = make([][]T, 0, maxInt(len(a), len(b)))
 
@@ -40,3 +32,13 @@ func maxInt(a, b int) int {
 
 	return b
 }
+
+func sigmoid(x float64) float64 {
+	// Split on the sign of x so that we never exponentiate a large positive number.
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+
+	e := math.Exp(x)
+	return e / (1 + e)
+}
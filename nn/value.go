@@ -1,19 +1,19 @@
 package nn
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
-	"time"
+	"sync/atomic"
 
 	"github.com/shopspring/decimal"
 )
 
 var (
-	// Since these values are used often; we might as well initialize rather than
-	// having to create every function call.
-	zero = decimal.NewFromFloat(0.0)
-	one  = decimal.NewFromFloat(1.0)
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrOutOfDomain    = errors.New("value out of domain")
 )
 
 type Kind int32
@@ -36,6 +36,14 @@ const (
 	OperationDiv
 	OperationPow
 	OperationReLu
+	OperationTanh
+	OperationSigmoid
+	OperationExp
+	OperationLog
+	OperationSqrt
+	OperationNeg
+	OperationAbs
+	OperationLeakyReLu
 )
 
 // String implements the stringer interface.
@@ -55,6 +63,22 @@ func (o Operation) String() string {
 		return "**"
 	case OperationReLu:
 		return "ReLu"
+	case OperationTanh:
+		return "Tanh"
+	case OperationSigmoid:
+		return "Sigmoid"
+	case OperationExp:
+		return "Exp"
+	case OperationLog:
+		return "Log"
+	case OperationSqrt:
+		return "Sqrt"
+	case OperationNeg:
+		return "Neg"
+	case OperationAbs:
+		return "Abs"
+	case OperationLeakyReLu:
+		return "LeakyReLu"
 	default:
 		return "unknown"
 	}
@@ -62,12 +86,37 @@ func (o Operation) String() string {
 
 var noop = func() {}
 
+// valueIDs is the process wide counter from which every value draws its id; ids are therefore unique &
+// monotonically increasing in order of creation.
+var valueIDs atomic.Int64
+
+// ResetValueIDs restarts value ids from the beginning. Calling it at the start of a run makes ids reproducible
+// across runs, so long as values are created in the same order, which allows graph snapshots to be diffed.
+//
+// Values created before the reset may share ids with those created after it, so it must not be called while
+// graphs built from older values are still in use.
+func ResetValueIDs() {
+	valueIDs.Store(0)
+}
+
+// NewValue creates a new value on the DefaultBackend.
 func NewValue(
 	value decimal.Decimal,
 	operation Operation,
 	kind Kind,
 	label string,
 	children ...*Value,
+) *Value {
+	return NewValueFromScalar(DefaultBackend.FromDecimal(value), operation, kind, label, children...)
+}
+
+// NewValueFromScalar creates a new value on the backend of the given scalar.
+func NewValueFromScalar(
+	value Scalar,
+	operation Operation,
+	kind Kind,
+	label string,
+	children ...*Value,
 ) *Value {
 	context := &context{
 		Label: label,
@@ -77,7 +126,7 @@ func NewValue(
 }
 
 func newValueWithContext(
-	value decimal.Decimal,
+	value Scalar,
 	operation Operation,
 	kind Kind,
 	context *context,
@@ -86,9 +135,15 @@ func newValueWithContext(
 	var (
 		previousSet []*Value
 		previousMap = make(map[*Value]struct{}, len(children))
+		// A value requires grad if any of its children does; leaves always require grad.
+		noGrad = len(children) > 0
 	)
 
 	for _, child := range children {
+		if !child.noGrad {
+			noGrad = false
+		}
+
 		if _, ok := previousMap[child]; ok {
 			continue
 		}
@@ -97,55 +152,92 @@ func newValueWithContext(
 		previousSet = append(previousSet, child)
 	}
 
+	// Values derived only from no-grad values never take part in a backward pass, so we don't link the children.
+	if noGrad {
+		previousSet = nil
+	}
+
 	return &Value{
 		data:      value,
 		kind:      kind,
 		operation: operation,
 		previous:  previousSet,
 		backward:  noop,
-		grad:      decimal.NewFromFloat(0.0),
-		id:        time.Now().UnixNano(),
+		grad:      value.Backend().FromFloat64(0),
+		id:        valueIDs.Add(1),
 		context:   context,
+		noGrad:    noGrad,
 	}
 }
 
 func (v *Value) Backward() {
+	topo := v.Topo()
+
+	v.grad = v.Backend().FromFloat64(1)
+	for i := len(topo) - 1; i >= 0; i-- {
+		node := topo[i]
+		node.backward()
+	}
+
+	// No-grad values are constants; drop whatever their trainable parents accumulated into them.
+	for _, node := range topo {
+		if node.noGrad {
+			node.grad = node.Backend().FromFloat64(0)
+		}
+	}
+}
+
+// Topo returns every node of the graph rooted at v in topological order, such that each node appears after all
+// of its children; the root is therefore always the last element.
+//
+// The graph is walked with an explicit stack rather than recursion, so arbitrarily deep graphs are safe.
+func (v *Value) Topo() []*Value {
+	type frame struct {
+		node *Value
+		// next is the index of the next child of node to visit.
+		next int
+	}
+
 	var (
-		s    = map[*Value]struct{}{}
-		topo []*Value
+		visited = map[*Value]struct{}{v: {}}
+		stack   = []frame{{node: v}}
+		topo    []*Value
 	)
 
-	var collect func(node *Value)
-	collect = func(node *Value) {
-		if _, ok := s[node]; ok {
-			return
-		}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
 
-		s[node] = struct{}{}
-		for _, c := range node.previous {
-			collect(c)
+		if top.next < len(top.node.previous) {
+			child := top.node.previous[top.next]
+			top.next++
+
+			if _, ok := visited[child]; ok {
+				continue
+			}
+
+			visited[child] = struct{}{}
+			stack = append(stack, frame{node: child})
+			continue
 		}
 
-		topo = append(topo, node)
+		// All children visited; the node can now be emitted.
+		topo = append(topo, top.node)
+		stack = stack[:len(stack)-1]
 	}
-	collect(v)
 
-	v.grad = one
-	for i := len(topo) - 1; i >= 0; i-- {
-		node := topo[i]
-		node.backward()
-	}
+	return topo
 }
 
 type Value struct {
 	kind      Kind
-	data      decimal.Decimal
+	data      Scalar
 	operation Operation
 	previous  []*Value
 	backward  func()
-	grad      decimal.Decimal
+	grad      Scalar
 	id        int64
 	context   *context
+	noGrad    bool
 }
 
 func (v *Value) Label() string {
@@ -156,7 +248,8 @@ func (v *Value) Label() string {
 	return v.context.String()
 }
 
-func (v *Value) layer() int {
+// Layer returns the index of the layer recorded in the context of the value, or -1 if it has no context.
+func (v *Value) Layer() int {
 	if v.context == nil {
 		return -1
 	}
@@ -179,18 +272,35 @@ func (v *Value) String() string {
 		op = "**"
 	case OperationReLu:
 		op = "relu"
+	case OperationTanh:
+		op = "tanh"
+	case OperationSigmoid:
+		op = "sigmoid"
+	case OperationExp:
+		op = "exp"
+	case OperationLog:
+		op = "log"
+	case OperationSqrt:
+		op = "sqrt"
+	case OperationNeg:
+		op = "neg"
+	case OperationAbs:
+		op = "abs"
+	case OperationLeakyReLu:
+		op = "leaky_relu"
 	}
 
-	va, _ := v.data.Float64()
-	gr, _ := v.grad.Float64()
-
-	return fmt.Sprintf("Value: %.3f, Op: %s, Grad: %.3f", va, op, gr)
+	return fmt.Sprintf("Value: %.3f, Op: %s, Grad: %.3f", v.data.Float64(), op, v.grad.Float64())
 }
 
 func (v *Value) Add(other *Value) *Value {
 	mergedContext := mergeContexts(v.context, other.context)
 	out := newValueWithContext(v.data.Add(other.data), OperationAdd, KindValue, mergedContext, v, other)
 
+	if out.noGrad {
+		return out
+	}
+
 	out.backward = func() {
 		v.grad = v.grad.Add(out.grad)
 		other.grad = other.grad.Add(out.grad)
@@ -203,9 +313,13 @@ func (v *Value) Sub(other *Value) *Value {
 	mergedContext := mergeContexts(v.context, other.context)
 	out := newValueWithContext(v.data.Sub(other.data), OperationSub, KindValue, mergedContext, v, other)
 
+	if out.noGrad {
+		return out
+	}
+
 	out.backward = func() {
 		v.grad = v.grad.Add(out.grad)
-		other.grad = other.grad.Add(out.grad)
+		other.grad = other.grad.Sub(out.grad)
 	}
 
 	return out
@@ -215,6 +329,10 @@ func (v *Value) Mul(other *Value) *Value {
 	mergedContext := mergeContexts(v.context, other.context)
 	out := newValueWithContext(v.data.Mul(other.data), OperationMul, KindValue, mergedContext, v, other)
 
+	if out.noGrad {
+		return out
+	}
+
 	out.backward = func() {
 		// Chain Rule: gradient at out node * differential over (v * other) w.r.t v.
 		dvdout := other.data.Mul(out.grad)
@@ -228,26 +346,52 @@ func (v *Value) Mul(other *Value) *Value {
 	return out
 }
 
+// Div divides the value by other; the process is terminated if other is zero, use TryDiv to handle the error.
 func (v *Value) Div(other *Value) *Value {
-	// TODO: validate.
-	if v, _ := other.data.Float64(); v == 0 {
-		log.Fatalf("Division by zero error; other is zero")
+	out, err := v.TryDiv(other)
+	if err != nil {
+		log.Fatalf("Division failed: %v", err)
+	}
+
+	return out
+}
+
+func (v *Value) TryDiv(other *Value) (*Value, error) {
+	if other.data.IsZero() {
+		return nil, ErrDivisionByZero
 	}
 
-	inverse := decimal.NewFromFloat(1.0)
 	mergedContext := mergeContexts(v.context, other.context)
-	inverseOther := newValueWithContext(inverse.Div(other.data), OperationDiv, KindValue, mergedContext, other.previous...)
+	out := newValueWithContext(v.data.Div(other.data), OperationDiv, KindValue, mergedContext, v, other)
 
-	out := v.Mul(inverseOther)
-	out.operation = OperationDiv
-	return out
+	if out.noGrad {
+		return out, nil
+	}
+
+	out.backward = func() {
+		// Chain Rule: gradient at out node * differential over (v / other) w.r.t v.
+		dvdout := out.grad.Div(other.data)
+		v.grad = v.grad.Add(dvdout)
+
+		// Chain Rule: gradient at out node * differential over (v / other) w.r.t other, i.e -v / other ** 2.
+		dodout := out.grad.Mul(v.data).Div(other.data.Mul(other.data))
+		other.grad = other.grad.Sub(dodout)
+	}
+
+	return out, nil
 }
 
 func (v *Value) Pow(x decimal.Decimal) *Value {
-	out := newValueWithContext(v.data.Pow(x), OperationPow, KindValue, v.context, v)
+	backend := v.Backend()
+	exponent := backend.FromDecimal(x)
+	out := newValueWithContext(v.data.Pow(exponent), OperationPow, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
 
 	out.backward = func() {
-		dvdout := x.Mul(v.data.Pow(x.Sub(one)))
+		dvdout := exponent.Mul(v.data.Pow(exponent.Sub(backend.FromFloat64(1))))
 		v.grad = v.grad.Add(dvdout.Mul(out.grad))
 	}
 
@@ -255,32 +399,255 @@ func (v *Value) Pow(x decimal.Decimal) *Value {
 }
 
 func (v *Value) ReLu() *Value {
-	out := newValueWithContext(max(zero, v.data), OperationReLu, KindValue, v.context, v)
+	var data = v.data
+	if data.Sign() <= 0 {
+		data = v.Backend().FromFloat64(0)
+	}
+
+	out := newValueWithContext(data, OperationReLu, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
 
 	out.backward = func() {
-		binary := func() decimal.Decimal {
-			if out.data.GreaterThan(zero) {
-				return one
-			}
+		if out.data.Sign() > 0 {
+			v.grad = v.grad.Add(out.grad)
+		}
+	}
+
+	return out
+}
+
+// LeakyReLu passes positive values through unchanged & scales negative values by slope.
+func (v *Value) LeakyReLu(slope float64) *Value {
+	var data = v.data
+	if data.Sign() < 0 {
+		data = data.Mul(v.Backend().FromFloat64(slope))
+	}
+
+	out := newValueWithContext(data, OperationLeakyReLu, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
+
+	out.backward = func() {
+		if v.data.Sign() > 0 {
+			v.grad = v.grad.Add(out.grad)
+			return
+		}
+
+		v.grad = v.grad.Add(out.grad.Mul(v.Backend().FromFloat64(slope)))
+	}
+
+	return out
+}
+
+func (v *Value) Tanh() *Value {
+	t := math.Tanh(v.Float64())
+	out := newValueWithContext(v.Backend().FromFloat64(t), OperationTanh, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
+
+	out.backward = func() {
+		// d(tanh(x))/dx = 1 - tanh(x) ** 2.
+		dvdout := v.Backend().FromFloat64(1 - t*t)
+		v.grad = v.grad.Add(dvdout.Mul(out.grad))
+	}
+
+	return out
+}
+
+func (v *Value) Sigmoid() *Value {
+	s := sigmoid(v.Float64())
+	out := newValueWithContext(v.Backend().FromFloat64(s), OperationSigmoid, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
+
+	out.backward = func() {
+		// d(sigmoid(x))/dx = sigmoid(x) * (1 - sigmoid(x)).
+		dvdout := v.Backend().FromFloat64(s * (1 - s))
+		v.grad = v.grad.Add(dvdout.Mul(out.grad))
+	}
+
+	return out
+}
+
+func (v *Value) Exp() *Value {
+	out := newValueWithContext(applyFloat64(v.data, math.Exp), OperationExp, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
+
+	out.backward = func() {
+		// d(e ** x)/dx = e ** x.
+		v.grad = v.grad.Add(out.data.Mul(out.grad))
+	}
+
+	return out
+}
+
+// Log returns the natural logarithm of the value; the process is terminated if the value is not strictly
+// positive, use TryLog to handle the error.
+func (v *Value) Log() *Value {
+	out, err := v.TryLog()
+	if err != nil {
+		log.Fatalf("Logarithm failed: %v", err)
+	}
+
+	return out
+}
+
+func (v *Value) TryLog() (*Value, error) {
+	x := v.Float64()
+	if x <= 0 {
+		return nil, fmt.Errorf("logarithm of non-positive value %f: %w", x, ErrOutOfDomain)
+	}
+
+	out := newValueWithContext(applyFloat64(v.data, math.Log), OperationLog, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out, nil
+	}
+
+	out.backward = func() {
+		// d(ln(x))/dx = 1 / x.
+		dvdout := v.Backend().FromFloat64(1 / x)
+		v.grad = v.grad.Add(dvdout.Mul(out.grad))
+	}
+
+	return out, nil
+}
+
+// Sqrt returns the square root of the value; the process is terminated if the value is negative, use TrySqrt to
+// handle the error.
+func (v *Value) Sqrt() *Value {
+	out, err := v.TrySqrt()
+	if err != nil {
+		log.Fatalf("Square root failed: %v", err)
+	}
+
+	return out
+}
+
+func (v *Value) TrySqrt() (*Value, error) {
+	x := v.Float64()
+	if x < 0 {
+		return nil, fmt.Errorf("square root of negative value %f: %w", x, ErrOutOfDomain)
+	}
+
+	s := math.Sqrt(x)
+	out := newValueWithContext(v.Backend().FromFloat64(s), OperationSqrt, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out, nil
+	}
+
+	out.backward = func() {
+		// d(sqrt(x))/dx = 1 / (2 * sqrt(x)); we take the subgradient at zero to be zero.
+		if s == 0 {
+			return
+		}
+
+		dvdout := v.Backend().FromFloat64(0.5 / s)
+		v.grad = v.grad.Add(dvdout.Mul(out.grad))
+	}
+
+	return out, nil
+}
+
+func (v *Value) Neg() *Value {
+	out := newValueWithContext(v.data.Neg(), OperationNeg, KindValue, v.context, v)
+
+	if out.noGrad {
+		return out
+	}
+
+	out.backward = func() {
+		v.grad = v.grad.Sub(out.grad)
+	}
+
+	return out
+}
+
+func (v *Value) Abs() *Value {
+	out := newValueWithContext(v.data.Abs(), OperationAbs, KindValue, v.context, v)
 
-			return zero
-		}()
+	if out.noGrad {
+		return out
+	}
 
-		v.grad = v.grad.Add(binary.Mul(out.grad))
+	out.backward = func() {
+		// The subgradient of |x| at zero is taken to be zero.
+		sign := v.Backend().FromFloat64(float64(v.data.Sign()))
+		v.grad = v.grad.Add(sign.Mul(out.grad))
 	}
 
 	return out
 }
 
 func (v *Value) Float64() float64 {
-	f, _ := v.data.Float64()
-	return f
+	return v.data.Float64()
+}
+
+// SetFloat64 overwrites the data held by the value; this is typically only useful on leaf values.
+func (v *Value) SetFloat64(f float64) {
+	v.data = v.Backend().FromFloat64(f)
+}
+
+func (v *Value) Grad() float64 {
+	return v.grad.Float64()
+}
+
+// SetGrad overwrites the gradient accumulated on the value, e.g when clipping gradients.
+func (v *Value) SetGrad(g float64) {
+	v.grad = v.Backend().FromFloat64(g)
 }
 
-func (v *Value) ApplyDescent(rate decimal.Decimal) {
-	apply := v.data.Mul(rate)
-	v.data = v.data.Add(apply)
+// ZeroGrad resets the gradient accumulated on the value.
+func (v *Value) ZeroGrad() {
+	v.grad = v.Backend().FromFloat64(0)
 }
 
-func (v *Value) ID() string { return strconv.Itoa(int(v.id)) }
+// ApplyDescent moves the data of the value against its gradient, i.e data = data - rate * grad.
+func (v *Value) ApplyDescent(rate float64) {
+	step := v.grad.Mul(v.Backend().FromFloat64(rate))
+	v.data = v.data.Sub(step)
+}
+
+// NoGrad returns a copy of the value in no-grad mode, i.e a constant which receives no gradient. A value computed
+// only from no-grad values is itself in no-grad mode: it holds no links to its children & no backward closure,
+// which saves memory when running inference. A value computed from a mix of no-grad & trainable values still
+// requires grad, so gradients keep flowing to the trainable values.
+func (v *Value) NoGrad() *Value {
+	return &Value{
+		data:      v.data,
+		kind:      v.kind,
+		operation: OperationNOOP,
+		backward:  noop,
+		grad:      v.Backend().FromFloat64(0),
+		id:        valueIDs.Add(1),
+		context:   v.context,
+		noGrad:    true,
+	}
+}
+
+// RequiresGrad returns false if the value is in no-grad mode.
+func (v *Value) RequiresGrad() bool { return !v.noGrad }
+
+// ApplyUpdate adds delta, as computed by an optimizer, to the data of the value.
+func (v *Value) ApplyUpdate(delta float64) {
+	v.data = v.data.Add(v.Backend().FromFloat64(delta))
+}
+
+// Backend returns the numeric backend the value's data is held in.
+func (v *Value) Backend() Backend { return v.data.Backend() }
+
+func (v *Value) ID() string { return strconv.FormatInt(v.id, 10) }
 func (v *Value) Kind() Kind { return v.kind }
@@ -2,10 +2,13 @@ package nn
 
 import (
 	"fmt"
+	"math"
+	"sync"
 	"testing"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValue(t *testing.T) {
@@ -18,65 +21,65 @@ func TestValue(t *testing.T) {
 		op            func(a, b *Value) *Value
 		operand       Operation
 		expectedValue *Value
-		expectedAGrad decimal.Decimal
-		expectedBGrad decimal.Decimal
+		expectedAGrad Scalar
+		expectedBGrad Scalar
 	}{
 		{
 			name: "simple_int_add",
-			a:    newValueWithContext(decimal.NewFromFloat(1.0), OperationNOOP, KindValue, nil),
-			b:    newValueWithContext(decimal.NewFromFloat(1.0), OperationNOOP, KindValue, nil),
+			a:    newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil),
+			b:    newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil),
 			op: func(a, b *Value) *Value {
 				return a.Add(b)
 			},
 			operand: OperationAdd,
 			applier: func(c *Value, operand Operation, previous ...*Value) *Value {
-				c.data = decimal.NewFromFloat(2.0)
-				c.grad = decimal.NewFromFloat(1.0)
+				c.data = Float64Backend.FromFloat64(2.0)
+				c.grad = Float64Backend.FromFloat64(1.0)
 				c.previous = previous
 				c.operation = operand
 				return c
 			},
-			expectedValue: newValueWithContext(decimal.NewFromFloat(1.0), OperationAdd, KindValue, nil),
-			expectedAGrad: decimal.NewFromFloat(1.0),
-			expectedBGrad: decimal.NewFromFloat(1.0),
+			expectedValue: newValueWithContext(Float64Backend.FromFloat64(1.0), OperationAdd, KindValue, nil),
+			expectedAGrad: Float64Backend.FromFloat64(1.0),
+			expectedBGrad: Float64Backend.FromFloat64(1.0),
 		},
 		{
 			name: "simple_int_mul",
-			a:    newValueWithContext(decimal.NewFromFloat(2.0), OperationNOOP, KindValue, nil),
-			b:    newValueWithContext(decimal.NewFromFloat(3.0), OperationNOOP, KindValue, nil),
+			a:    newValueWithContext(Float64Backend.FromFloat64(2.0), OperationNOOP, KindValue, nil),
+			b:    newValueWithContext(Float64Backend.FromFloat64(3.0), OperationNOOP, KindValue, nil),
 			op: func(a, b *Value) *Value {
 				return a.Mul(b)
 			},
 			operand: OperationMul,
 			applier: func(c *Value, operand Operation, previous ...*Value) *Value {
-				c.data = decimal.NewFromFloat(6.0)
-				c.grad = decimal.NewFromFloat(1.0)
+				c.data = Float64Backend.FromFloat64(6.0)
+				c.grad = Float64Backend.FromFloat64(1.0)
 				c.previous = previous
 				c.operation = operand
 				return c
 			},
-			expectedValue: newValueWithContext(decimal.NewFromFloat(6.0), OperationMul, KindValue, nil),
-			expectedAGrad: decimal.NewFromFloat(3.0),
-			expectedBGrad: decimal.NewFromFloat(2.0),
+			expectedValue: newValueWithContext(Float64Backend.FromFloat64(6.0), OperationMul, KindValue, nil),
+			expectedAGrad: Float64Backend.FromFloat64(3.0),
+			expectedBGrad: Float64Backend.FromFloat64(2.0),
 		},
 		{
 			name: "simple_int_sub",
-			a:    newValueWithContext(decimal.NewFromFloat(2.0), OperationNOOP, KindValue, nil),
-			b:    newValueWithContext(decimal.NewFromFloat(3.0), OperationNOOP, KindValue, nil),
+			a:    newValueWithContext(Float64Backend.FromFloat64(2.0), OperationNOOP, KindValue, nil),
+			b:    newValueWithContext(Float64Backend.FromFloat64(3.0), OperationNOOP, KindValue, nil),
 			op: func(a, b *Value) *Value {
 				return a.Sub(b)
 			},
 			operand: OperationSub,
 			applier: func(c *Value, operand Operation, previous ...*Value) *Value {
-				c.data = decimal.NewFromFloat(-1.0)
-				c.grad = decimal.NewFromFloat(1.0)
+				c.data = Float64Backend.FromFloat64(-1.0)
+				c.grad = Float64Backend.FromFloat64(1.0)
 				c.previous = previous
 				c.operation = operand
 				return c
 			},
-			expectedValue: newValueWithContext(decimal.NewFromFloat(-1.0), OperationSub, KindValue, nil),
-			expectedAGrad: decimal.NewFromFloat(1.0),
-			expectedBGrad: decimal.NewFromFloat(1.0),
+			expectedValue: newValueWithContext(Float64Backend.FromFloat64(-1.0), OperationSub, KindValue, nil),
+			expectedAGrad: Float64Backend.FromFloat64(1.0),
+			expectedBGrad: Float64Backend.FromFloat64(-1.0),
 		},
 	}
 
@@ -106,3 +109,329 @@ func TestValue(t *testing.T) {
 		})
 	}
 }
+
+func TestValueUnary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		x            float64
+		op           func(v *Value) *Value
+		operation    Operation
+		expectedData float64
+		expectedGrad float64
+	}{
+		{
+			name:         "tanh",
+			x:            0.5,
+			op:           (*Value).Tanh,
+			operation:    OperationTanh,
+			expectedData: math.Tanh(0.5),
+			expectedGrad: 1 - math.Tanh(0.5)*math.Tanh(0.5),
+		},
+		{
+			name:         "sigmoid",
+			x:            0.0,
+			op:           (*Value).Sigmoid,
+			operation:    OperationSigmoid,
+			expectedData: 0.5,
+			expectedGrad: 0.25,
+		},
+		{
+			name:         "exp",
+			x:            1.0,
+			op:           (*Value).Exp,
+			operation:    OperationExp,
+			expectedData: math.E,
+			expectedGrad: math.E,
+		},
+		{
+			name:         "log",
+			x:            2.0,
+			op:           (*Value).Log,
+			operation:    OperationLog,
+			expectedData: math.Ln2,
+			expectedGrad: 0.5,
+		},
+		{
+			name:         "sqrt",
+			x:            4.0,
+			op:           (*Value).Sqrt,
+			operation:    OperationSqrt,
+			expectedData: 2.0,
+			expectedGrad: 0.25,
+		},
+		{
+			name:         "neg",
+			x:            3.0,
+			op:           (*Value).Neg,
+			operation:    OperationNeg,
+			expectedData: -3.0,
+			expectedGrad: -1.0,
+		},
+		{
+			name:         "leaky_relu_negative",
+			x:            -2.0,
+			op:           func(v *Value) *Value { return v.LeakyReLu(0.1) },
+			operation:    OperationLeakyReLu,
+			expectedData: -0.2,
+			expectedGrad: 0.1,
+		},
+		{
+			name:         "leaky_relu_positive",
+			x:            2.0,
+			op:           func(v *Value) *Value { return v.LeakyReLu(0.1) },
+			operation:    OperationLeakyReLu,
+			expectedData: 2.0,
+			expectedGrad: 1.0,
+		},
+		{
+			name:         "abs_negative",
+			x:            -3.0,
+			op:           (*Value).Abs,
+			operation:    OperationAbs,
+			expectedData: 3.0,
+			expectedGrad: -1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			x := newValueWithContext(Float64Backend.FromFloat64(tt.x), OperationNOOP, KindValue, nil)
+
+			out := tt.op(x)
+			out.Backward()
+
+			assert.InDelta(t, tt.expectedData, out.Float64(), 1e-9)
+			assert.InDelta(t, tt.expectedGrad, x.Grad(), 1e-9)
+			assert.Equal(t, tt.operation, out.operation)
+			assert.Equal(t, []*Value{x}, out.previous)
+		})
+	}
+}
+
+func TestValueTopo(t *testing.T) {
+	t.Parallel()
+
+	a := newValueWithContext(Float64Backend.FromFloat64(2.0), OperationNOOP, KindValue, nil)
+	b := newValueWithContext(Float64Backend.FromFloat64(3.0), OperationNOOP, KindValue, nil)
+
+	// Diamond: a is shared by both branches.
+	c := a.Mul(b)
+	d := a.Add(c)
+	e := d.Mul(c)
+
+	topo := e.Topo()
+	require.Len(t, topo, 5)
+	assert.Equal(t, e, topo[len(topo)-1])
+
+	var position = make(map[*Value]int, len(topo))
+	for i, node := range topo {
+		position[node] = i
+	}
+
+	for _, node := range topo {
+		for _, child := range node.previous {
+			assert.Less(t, position[child], position[node], "child must precede its parent")
+		}
+	}
+}
+
+func TestValueBackwardDeepChain(t *testing.T) {
+	t.Parallel()
+
+	const depth = 200_000
+
+	x := newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil)
+	one := newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil)
+
+	out := x
+	for i := 0; i < depth; i++ {
+		out = out.Add(one)
+	}
+	out.Backward()
+
+	assert.Len(t, out.Topo(), depth+2)
+	assert.Equal(t, float64(depth+1), out.Float64())
+	assert.Equal(t, 1.0, x.Grad())
+	assert.Equal(t, float64(depth), one.Grad())
+}
+
+func TestValueIDsAreUnique(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers   = 8
+		perWorker = 1000
+	)
+
+	var (
+		wg  sync.WaitGroup
+		ids = make([][]int64, workers)
+	)
+
+	for w := 0; w < workers; w++ {
+		w := w
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < perWorker; i++ {
+				v := newValueWithContext(Float64Backend.FromFloat64(0), OperationNOOP, KindValue, nil)
+				ids[w] = append(ids[w], v.id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var seen = make(map[int64]struct{}, workers*perWorker)
+	for _, workerIDs := range ids {
+		for i, id := range workerIDs {
+			if i > 0 {
+				assert.Greater(t, id, workerIDs[i-1], "ids must increase monotonically")
+			}
+
+			seen[id] = struct{}{}
+		}
+	}
+
+	assert.Len(t, seen, workers*perWorker)
+}
+
+// TestResetValueIDs is deliberately not parallel; resetting the counter whilst other tests build graphs would
+// cause ids to collide.
+func TestResetValueIDs(t *testing.T) {
+	build := func() []string {
+		a := NewValue(decimal.NewFromFloat(1.0), OperationNOOP, KindInput, "a")
+		b := NewValue(decimal.NewFromFloat(2.0), OperationNOOP, KindInput, "b")
+		out := a.Mul(b).Tanh()
+
+		var ids []string
+		for _, node := range out.Topo() {
+			ids = append(ids, node.ID())
+		}
+
+		return ids
+	}
+
+	ResetValueIDs()
+	first := build()
+
+	ResetValueIDs()
+	second := build()
+
+	assert.Equal(t, []string{"1", "2", "3", "4"}, first)
+	assert.Equal(t, first, second)
+}
+
+func TestValueTryErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		op          func() (*Value, error)
+		expectedErr error
+	}{
+		{
+			name: "div_by_zero",
+			op: func() (*Value, error) {
+				a := newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil)
+				b := newValueWithContext(Float64Backend.FromFloat64(0.0), OperationNOOP, KindValue, nil)
+				return a.TryDiv(b)
+			},
+			expectedErr: ErrDivisionByZero,
+		},
+		{
+			name: "div_by_zero_decimal",
+			op: func() (*Value, error) {
+				a := newValueWithContext(DecimalBackend.FromFloat64(1.0), OperationNOOP, KindValue, nil)
+				b := newValueWithContext(DecimalBackend.FromFloat64(0.0), OperationNOOP, KindValue, nil)
+				return a.TryDiv(b)
+			},
+			expectedErr: ErrDivisionByZero,
+		},
+		{
+			name: "log_of_zero",
+			op: func() (*Value, error) {
+				return newValueWithContext(Float64Backend.FromFloat64(0.0), OperationNOOP, KindValue, nil).TryLog()
+			},
+			expectedErr: ErrOutOfDomain,
+		},
+		{
+			name: "sqrt_of_negative",
+			op: func() (*Value, error) {
+				return newValueWithContext(Float64Backend.FromFloat64(-1.0), OperationNOOP, KindValue, nil).TrySqrt()
+			},
+			expectedErr: ErrOutOfDomain,
+		},
+		{
+			name: "div_ok",
+			op: func() (*Value, error) {
+				a := newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindValue, nil)
+				b := newValueWithContext(Float64Backend.FromFloat64(4.0), OperationNOOP, KindValue, nil)
+				return a.TryDiv(b)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := tt.op()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, out)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, out)
+		})
+	}
+}
+
+func TestValueNoGrad(t *testing.T) {
+	t.Parallel()
+
+	a := newValueWithContext(Float64Backend.FromFloat64(2.0), OperationNOOP, KindValue, nil)
+	b := newValueWithContext(Float64Backend.FromFloat64(3.0), OperationNOOP, KindValue, nil)
+
+	noGradA, noGradB := a.NoGrad(), b.NoGrad()
+	assert.False(t, noGradA.RequiresGrad())
+	assert.True(t, a.RequiresGrad())
+
+	out := noGradA.Mul(noGradB).Add(noGradB).Tanh()
+	assert.False(t, out.RequiresGrad())
+	assert.Empty(t, out.previous)
+	assert.InDelta(t, math.Tanh(9.0), out.Float64(), 1e-9)
+
+	out.Backward()
+	assert.Zero(t, a.Grad())
+	assert.Zero(t, b.Grad())
+}
+
+func TestValueNoGradMixedWithTrainable(t *testing.T) {
+	t.Parallel()
+
+	w := newValueWithContext(Float64Backend.FromFloat64(2.0), OperationNOOP, KindWeight, nil)
+	x := newValueWithContext(Float64Backend.FromFloat64(3.0), OperationNOOP, KindInput, nil)
+	target := newValueWithContext(Float64Backend.FromFloat64(1.0), OperationNOOP, KindInput, nil).NoGrad()
+
+	// (w * x - target) ** 2, with a no-grad target as passed by a caller to a loss.
+	loss := w.Mul(x).Sub(target).Pow(decimal.NewFromInt(2))
+	assert.True(t, loss.RequiresGrad())
+
+	loss.Backward()
+
+	// d(loss)/dw = 2 * (w * x - target) * x.
+	assert.InDelta(t, 2*(6.0-1.0)*3.0, w.Grad(), 1e-9)
+	assert.Zero(t, target.Grad())
+}
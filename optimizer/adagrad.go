@@ -0,0 +1,171 @@
+package optimizer
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+	"sync"
+)
+
+const (
+	// DefaultAdagradLearningRate is the usual learning rate of Adagrad, for use in AdagradConfig.
+	DefaultAdagradLearningRate = 0.01
+	defaultAdagradEpsilon      = 1e-10
+
+	adagradStateName = "adagrad"
+	sumBuffer        = "sum"
+)
+
+var (
+	_ nn.Optimizer               = new(AdagradOptimizer)
+	_ nn.OptimizerStateMarshaler = new(AdagradOptimizer)
+)
+
+type AdagradConfig struct {
+	// LearningRate must be positive; DefaultAdagradLearningRate of 0.01 is a common choice.
+	LearningRate float64
+	// LearningRateDecay decays the learning rate as learning_rate / (1 + (t - 1) * decay); zero disables decay.
+	LearningRateDecay float64
+	// InitialAccumulatorValue is the starting value of the per parameter sum of squared gradients.
+	InitialAccumulatorValue float64
+	// Epsilon is added to the denominator for numerical stability; defaults to 1e-10 if zero.
+	Epsilon float64
+	// WeightDecay is the coefficient of the L2 penalty added to the gradient; zero disables weight decay.
+	WeightDecay float64
+	// Groups override the learning rate & weight decay of the params they select.
+	Groups []ParamGroup
+}
+
+func NewAdagrad(cfg AdagradConfig) (*AdagradOptimizer, error) {
+	if err := checkLearningRate(cfg.LearningRate); err != nil {
+		return nil, err
+	}
+
+	epsilon := floatOrDefault(cfg.Epsilon, defaultAdagradEpsilon)
+
+	switch {
+	case !(cfg.LearningRateDecay >= 0) || math.IsInf(cfg.LearningRateDecay, 1):
+		return nil, fmt.Errorf(
+			"learning rate decay %f must be finite & not negative: %w", cfg.LearningRateDecay, ErrInvalidHyperparameter,
+		)
+	case !(cfg.InitialAccumulatorValue >= 0) || math.IsInf(cfg.InitialAccumulatorValue, 1):
+		return nil, fmt.Errorf(
+			"initial accumulator value %f must be finite & not negative: %w",
+			cfg.InitialAccumulatorValue, ErrInvalidHyperparameter,
+		)
+	case !(epsilon >= 0) || math.IsInf(epsilon, 1):
+		return nil, fmt.Errorf("epsilon %f must be finite & not negative: %w", epsilon, ErrInvalidHyperparameter)
+	}
+
+	groups, err := newParamGroups(cfg.Groups, cfg.WeightDecay)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdagradOptimizer{
+		learningRate:            cfg.LearningRate,
+		learningRateDecay:       cfg.LearningRateDecay,
+		initialAccumulatorValue: cfg.InitialAccumulatorValue,
+		epsilon:                 epsilon,
+		groups:                  groups,
+		sums:                    make(map[*nn.Value]float64),
+	}, nil
+}
+
+// AdagradOptimizer scales the learning rate of each param by the inverse root of its accumulated squared
+// gradients, so frequently updated params take smaller steps:
+//
+//	sum   = sum + grad ** 2
+//	param = param - learning_rate * grad / (sqrt(sum) + epsilon)
+type AdagradOptimizer struct {
+	learningRate            float64
+	learningRateDecay       float64
+	initialAccumulatorValue float64
+	epsilon                 float64
+	groups                  paramGroups
+	sums                    map[*nn.Value]float64
+	steps                   int
+	mu                      sync.RWMutex
+}
+
+func (a *AdagradOptimizer) Step(params []*nn.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.steps++
+	learningRate := a.learningRate / (1 + float64(a.steps-1)*a.learningRateDecay)
+
+	for _, p := range params {
+		h := a.groups.resolve(p)
+		grad := p.Grad() + h.weightDecay*p.Float64()
+
+		sum, ok := a.sums[p]
+		if !ok {
+			sum = a.initialAccumulatorValue
+		}
+
+		sum += grad * grad
+		a.sums[p] = sum
+
+		p.ApplyUpdate(-learningRate * h.learningRateScale * grad / (math.Sqrt(sum) + a.epsilon))
+	}
+}
+
+func (a *AdagradOptimizer) ZeroGrad(params []*nn.Value) {
+	zeroGrad(params)
+}
+
+func (a *AdagradOptimizer) LearningRate() float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.learningRate
+}
+
+func (a *AdagradOptimizer) SetLearningRate(rate float64) {
+	if !validLearningRate(rate) {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.learningRate = rate
+}
+
+// Steps returns the number of steps taken so far.
+func (a *AdagradOptimizer) Steps() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.steps
+}
+
+// MarshalState serializes the step count & accumulated squared gradients of the optimizer, keyed by the names of
+// the params.
+func (a *AdagradOptimizer) MarshalState(params []nn.NamedParameter) ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return marshalState(adagradStateName, a.learningRate, a.steps, map[string]map[*nn.Value]float64{
+		sumBuffer: a.sums,
+	}, params)
+}
+
+// UnmarshalState replaces the learning rate, step count & accumulated squared gradients of the optimizer with
+// those previously serialized by MarshalState.
+func (a *AdagradOptimizer) UnmarshalState(data []byte, params []nn.NamedParameter) error {
+	decoded, err := unmarshalState(data, adagradStateName, params)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.learningRate = decoded.learningRate
+	a.steps = decoded.steps
+	a.sums = decoded.buffer(sumBuffer)
+
+	return nil
+}
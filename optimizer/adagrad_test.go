@@ -0,0 +1,81 @@
+package optimizer
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdagradOptimizerStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cfg         AdagradConfig
+		expected    []float64
+		expectedErr error
+	}{
+		{
+			name:     "plain",
+			cfg:      AdagradConfig{LearningRate: 0.1},
+			expected: []float64{0.9, 0.829289322},
+		},
+		{
+			name:     "learning_rate_decay",
+			cfg:      AdagradConfig{LearningRate: 0.1, LearningRateDecay: 0.5},
+			expected: []float64{0.9, 0.852859548},
+		},
+		{
+			name:     "initial_accumulator_value",
+			cfg:      AdagradConfig{LearningRate: 0.1, InitialAccumulatorValue: 5},
+			expected: []float64{0.933333333},
+		},
+		{
+			name:        "negative_decay",
+			cfg:         AdagradConfig{LearningRate: DefaultAdagradLearningRate, LearningRateDecay: -1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_decay",
+			cfg:         AdagradConfig{LearningRate: DefaultAdagradLearningRate, LearningRateDecay: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adagrad, err := NewAdagrad(tt.cfg)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			p := nn.NewValueFromScalar(nn.Float64Backend.FromFloat64(1.0), nn.OperationNOOP, nn.KindWeight, "p")
+			for _, expected := range tt.expected {
+				setGrad(p, 2.0)
+				adagrad.Step([]*nn.Value{p})
+
+				assert.InDelta(t, expected, p.Float64(), 1e-8)
+			}
+
+			assert.Equal(t, len(tt.expected), adagrad.Steps())
+		})
+	}
+}
+
+func TestAdagradOptimizerTrainsNeuralNetwork(t *testing.T) {
+	t.Parallel()
+
+	adagrad, err := NewAdagrad(AdagradConfig{LearningRate: 0.5})
+	require.NoError(t, err)
+
+	assertTrainsLinearRegression(t, adagrad)
+}
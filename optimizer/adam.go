@@ -0,0 +1,204 @@
+package optimizer
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+	"sync"
+)
+
+const (
+	// DefaultAdamLearningRate is the usual learning rate of Adam, for use in AdamConfig.
+	DefaultAdamLearningRate = 0.001
+	defaultAdamBeta1        = 0.9
+	defaultAdamBeta2        = 0.999
+	defaultAdamEpsilon      = 1e-8
+
+	firstMomentBuffer  = "first_moment"
+	secondMomentBuffer = "second_moment"
+)
+
+var (
+	_ nn.Optimizer               = new(AdamOptimizer)
+	_ nn.OptimizerStateMarshaler = new(AdamOptimizer)
+)
+
+type AdamConfig struct {
+	// LearningRate must be positive; DefaultAdamLearningRate of 0.001 is a common choice.
+	LearningRate float64
+	// Beta1 is the decay rate of the first moment (mean) of the gradients, within (0, 1); defaults to 0.9 if zero.
+	Beta1 float64
+	// Beta2 is the decay rate of the second moment (uncentered variance) of the gradients, within (0, 1); defaults
+	// to 0.999 if zero.
+	Beta2 float64
+	// Epsilon is added to the denominator for numerical stability; defaults to 1e-8 if zero.
+	Epsilon float64
+	// WeightDecay is the weight decay coefficient; zero disables weight decay.
+	WeightDecay float64
+	// Groups override the learning rate & weight decay of the params they select.
+	Groups []ParamGroup
+}
+
+// NewAdam returns an Adam optimizer; weight decay, if any, is applied as an L2 penalty added to the gradient.
+func NewAdam(cfg AdamConfig) (*AdamOptimizer, error) {
+	return newAdam(cfg, false)
+}
+
+// NewAdamW returns an AdamW optimizer; weight decay is decoupled from the gradient & applied directly to the
+// params, so it isn't scaled by the adaptive learning rate.
+func NewAdamW(cfg AdamConfig) (*AdamOptimizer, error) {
+	return newAdam(cfg, true)
+}
+
+func newAdam(cfg AdamConfig, decoupled bool) (*AdamOptimizer, error) {
+	if err := checkLearningRate(cfg.LearningRate); err != nil {
+		return nil, err
+	}
+
+	beta1 := floatOrDefault(cfg.Beta1, defaultAdamBeta1)
+	beta2 := floatOrDefault(cfg.Beta2, defaultAdamBeta2)
+	epsilon := floatOrDefault(cfg.Epsilon, defaultAdamEpsilon)
+
+	switch {
+	case !(beta1 > 0 && beta1 < 1):
+		return nil, fmt.Errorf("beta1 %f must be within (0, 1): %w", beta1, ErrInvalidHyperparameter)
+	case !(beta2 > 0 && beta2 < 1):
+		return nil, fmt.Errorf("beta2 %f must be within (0, 1): %w", beta2, ErrInvalidHyperparameter)
+	case !(epsilon >= 0) || math.IsInf(epsilon, 1):
+		return nil, fmt.Errorf("epsilon %f must be finite & not negative: %w", epsilon, ErrInvalidHyperparameter)
+	}
+
+	groups, err := newParamGroups(cfg.Groups, cfg.WeightDecay)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdamOptimizer{
+		learningRate:  cfg.LearningRate,
+		beta1:         beta1,
+		beta2:         beta2,
+		epsilon:       epsilon,
+		groups:        groups,
+		decoupled:     decoupled,
+		firstMoments:  make(map[*nn.Value]float64),
+		secondMoments: make(map[*nn.Value]float64),
+	}, nil
+}
+
+// AdamOptimizer implements Adam & AdamW:
+//
+//	m     = beta1 * m + (1 - beta1) * grad
+//	v     = beta2 * v + (1 - beta2) * grad ** 2
+//	param = param - learning_rate * (m / (1 - beta1 ** t)) / (sqrt(v / (1 - beta2 ** t)) + epsilon)
+type AdamOptimizer struct {
+	learningRate  float64
+	beta1         float64
+	beta2         float64
+	epsilon       float64
+	groups        paramGroups
+	decoupled     bool
+	firstMoments  map[*nn.Value]float64
+	secondMoments map[*nn.Value]float64
+	steps         int
+	mu            sync.RWMutex
+}
+
+func (a *AdamOptimizer) Step(params []*nn.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.steps++
+
+	// Bias corrections for the moments, which are initialized at zero.
+	firstCorrection := 1 - math.Pow(a.beta1, float64(a.steps))
+	secondCorrection := 1 - math.Pow(a.beta2, float64(a.steps))
+
+	for _, p := range params {
+		h := a.groups.resolve(p)
+		learningRate := a.learningRate * h.learningRateScale
+
+		grad := p.Grad()
+		if h.weightDecay != 0 && !a.decoupled {
+			grad += h.weightDecay * p.Float64()
+		}
+
+		m := a.beta1*a.firstMoments[p] + (1-a.beta1)*grad
+		v := a.beta2*a.secondMoments[p] + (1-a.beta2)*grad*grad
+		a.firstMoments[p], a.secondMoments[p] = m, v
+
+		update := -learningRate * (m / firstCorrection) / (math.Sqrt(v/secondCorrection) + a.epsilon)
+		if h.weightDecay != 0 && a.decoupled {
+			update -= learningRate * h.weightDecay * p.Float64()
+		}
+
+		p.ApplyUpdate(update)
+	}
+}
+
+func (a *AdamOptimizer) ZeroGrad(params []*nn.Value) {
+	zeroGrad(params)
+}
+
+func (a *AdamOptimizer) LearningRate() float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.learningRate
+}
+
+func (a *AdamOptimizer) SetLearningRate(rate float64) {
+	if !validLearningRate(rate) {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.learningRate = rate
+}
+
+// Steps returns the number of steps taken so far.
+func (a *AdamOptimizer) Steps() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.steps
+}
+
+// MarshalState serializes the step count & moments of the optimizer, keyed by the names of the params.
+func (a *AdamOptimizer) MarshalState(params []nn.NamedParameter) ([]byte, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return marshalState(a.stateName(), a.learningRate, a.steps, map[string]map[*nn.Value]float64{
+		firstMomentBuffer:  a.firstMoments,
+		secondMomentBuffer: a.secondMoments,
+	}, params)
+}
+
+// UnmarshalState replaces the learning rate, step count & moments of the optimizer with those previously
+// serialized by MarshalState. Adam state cannot be restored into AdamW & vice versa.
+func (a *AdamOptimizer) UnmarshalState(data []byte, params []nn.NamedParameter) error {
+	decoded, err := unmarshalState(data, a.stateName(), params)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.learningRate = decoded.learningRate
+	a.steps = decoded.steps
+	a.firstMoments = decoded.buffer(firstMomentBuffer)
+	a.secondMoments = decoded.buffer(secondMomentBuffer)
+
+	return nil
+}
+
+func (a *AdamOptimizer) stateName() string {
+	if a.decoupled {
+		return "adamw"
+	}
+
+	return "adam"
+}
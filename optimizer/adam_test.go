@@ -0,0 +1,160 @@
+package optimizer
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdam(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cfg         AdamConfig
+		expectedErr error
+	}{
+		{
+			name: "default",
+			cfg:  AdamConfig{LearningRate: DefaultAdamLearningRate},
+		},
+		{
+			name:        "zero_learning_rate",
+			expectedErr: ErrInvalidLearningRate,
+		},
+		{
+			name:        "negative_learning_rate",
+			cfg:         AdamConfig{LearningRate: -1},
+			expectedErr: ErrInvalidLearningRate,
+		},
+		{
+			name:        "beta1_out_of_range",
+			cfg:         AdamConfig{LearningRate: DefaultAdamLearningRate, Beta1: 1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "beta2_out_of_range",
+			cfg:         AdamConfig{LearningRate: DefaultAdamLearningRate, Beta2: -0.1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_beta1",
+			cfg:         AdamConfig{LearningRate: DefaultAdamLearningRate, Beta1: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_epsilon",
+			cfg:         AdamConfig{LearningRate: DefaultAdamLearningRate, Epsilon: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "negative_weight_decay",
+			cfg:         AdamConfig{LearningRate: DefaultAdamLearningRate, WeightDecay: -0.1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAdam(tt.cfg)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAdamOptimizerStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		constructor func(cfg AdamConfig) (*AdamOptimizer, error)
+		cfg         AdamConfig
+		grad        float64
+		expected    float64
+	}{
+		{
+			// With bias correction the first step has magnitude learning_rate regardless of the gradient's scale.
+			name:        "adam_first_step",
+			constructor: NewAdam,
+			cfg:         AdamConfig{LearningRate: 0.1},
+			grad:        20,
+			expected:    0.9,
+		},
+		{
+			// Coupled weight decay is folded into the gradient, so is normalized away by the second moment.
+			name:        "adam_weight_decay",
+			constructor: NewAdam,
+			cfg:         AdamConfig{LearningRate: 0.1, WeightDecay: 0.1},
+			grad:        0,
+			expected:    0.9,
+		},
+		{
+			// Decoupled weight decay shrinks the param directly by learning_rate * weight_decay.
+			name:        "adamw_weight_decay",
+			constructor: NewAdamW,
+			cfg:         AdamConfig{LearningRate: 0.1, WeightDecay: 0.1},
+			grad:        0,
+			expected:    0.99,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adam, err := tt.constructor(tt.cfg)
+			require.NoError(t, err)
+
+			p := newParamWithGrad(nn.Float64Backend, 1.0, tt.grad)
+			adam.Step([]*nn.Value{p})
+
+			assert.InDelta(t, tt.expected, p.Float64(), 1e-6)
+			assert.Equal(t, 1, adam.Steps())
+		})
+	}
+}
+
+func TestAdamOptimizerTrainsNeuralNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		constructor func(cfg AdamConfig) (*AdamOptimizer, error)
+	}{
+		{
+			name:        "adam",
+			constructor: NewAdam,
+		},
+		{
+			name:        "adamw",
+			constructor: NewAdamW,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adam, err := tt.constructor(AdamConfig{LearningRate: 0.05, WeightDecay: 1e-4})
+			require.NoError(t, err)
+
+			assertTrainsLinearRegression(t, adam)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package optimizer
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+// ParamGroup overrides the hyperparameters of the params it selects, e.g to exclude biases from weight decay or
+// to train the last layer at a higher learning rate. A param belongs to the first group whose selector matches
+// it; params matched by no group use the hyperparameters of the optimizer.
+type ParamGroup struct {
+	Name     string
+	Selector func(p *nn.Value) bool
+	// LearningRateScale multiplies the learning rate of the optimizer for the group; zero is unset & means 1, so a
+	// group is frozen with Frozen rather than a zero scale.
+	LearningRateScale float64
+	// Frozen trains the group at a zero learning rate, leaving its params unchanged by every step; it cannot be
+	// combined with a LearningRateScale.
+	Frozen bool
+	// WeightDecay is the weight decay coefficient of the group, replacing that of the optimizer; nil is unset &
+	// inherits the weight decay of the optimizer, whereas WeightDecay(0) disables weight decay for the group.
+	WeightDecay *float64
+}
+
+// WeightDecay returns the coefficient for the WeightDecay of a ParamGroup.
+func WeightDecay(coefficient float64) *float64 {
+	return &coefficient
+}
+
+// SelectKind selects the params of any of the given kinds, e.g nn.KindBias.
+func SelectKind(kinds ...nn.Kind) func(p *nn.Value) bool {
+	return func(p *nn.Value) bool {
+		for _, kind := range kinds {
+			if p.Kind() == kind {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// SelectLayers selects the params belonging to any of the given layer indexes.
+func SelectLayers(layers ...int) func(p *nn.Value) bool {
+	return func(p *nn.Value) bool {
+		for _, layer := range layers {
+			if p.Layer() == layer {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// hyperparameters are the per param hyperparameters resolved from the groups.
+type hyperparameters struct {
+	learningRateScale float64
+	weightDecay       float64
+}
+
+type paramGroups struct {
+	groups   []ParamGroup
+	fallback hyperparameters
+}
+
+func newParamGroups(groups []ParamGroup, weightDecay float64) (paramGroups, error) {
+	if !validWeightDecay(weightDecay) {
+		return paramGroups{}, fmt.Errorf(
+			"weight decay %f must be finite & not negative: %w", weightDecay, ErrInvalidHyperparameter,
+		)
+	}
+
+	var resolved = make([]ParamGroup, 0, len(groups))
+	for i, g := range groups {
+		switch {
+		case g.Selector == nil:
+			return paramGroups{}, fmt.Errorf("group %d (%s) has no selector: %w", i, g.Name, ErrInvalidHyperparameter)
+		case !(g.LearningRateScale >= 0) || math.IsInf(g.LearningRateScale, 1):
+			return paramGroups{}, fmt.Errorf(
+				"group %d (%s) learning rate scale %f must be finite & not negative: %w",
+				i, g.Name, g.LearningRateScale, ErrInvalidHyperparameter,
+			)
+		case g.Frozen && g.LearningRateScale != 0:
+			return paramGroups{}, fmt.Errorf(
+				"group %d (%s) is frozen but has learning rate scale %f: %w",
+				i, g.Name, g.LearningRateScale, ErrInvalidHyperparameter,
+			)
+		case g.WeightDecay != nil && !validWeightDecay(*g.WeightDecay):
+			return paramGroups{}, fmt.Errorf(
+				"group %d (%s) weight decay %f must be finite & not negative: %w",
+				i, g.Name, *g.WeightDecay, ErrInvalidHyperparameter,
+			)
+		}
+
+		if !g.Frozen {
+			g.LearningRateScale = floatOrDefault(g.LearningRateScale, 1)
+		}
+		if g.WeightDecay == nil {
+			g.WeightDecay = WeightDecay(weightDecay)
+		}
+		resolved = append(resolved, g)
+	}
+
+	return paramGroups{
+		groups: resolved,
+		fallback: hyperparameters{
+			learningRateScale: 1,
+			weightDecay:       weightDecay,
+		},
+	}, nil
+}
+
+func (g paramGroups) resolve(p *nn.Value) hyperparameters {
+	for _, group := range g.groups {
+		if group.Selector(p) {
+			return hyperparameters{
+				learningRateScale: group.LearningRateScale,
+				weightDecay:       *group.WeightDecay,
+			}
+		}
+	}
+
+	return g.fallback
+}
+
+// validWeightDecay reports whether the coefficient can be used as a weight decay, i.e it is finite & not negative.
+func validWeightDecay(coefficient float64) bool {
+	return coefficient >= 0 && !math.IsInf(coefficient, 1)
+}
@@ -0,0 +1,186 @@
+package optimizer
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectors(t *testing.T) {
+	t.Parallel()
+
+	mlp := nn.NewMLPFromConfig(nn.NeuralNetworkConfig{InputShape: 2, Shape: []int{2, 1}, Seed: 1})
+
+	var biases, lastLayer int
+	for _, p := range mlp.Parameters() {
+		if SelectKind(nn.KindBias)(p) {
+			biases++
+			assert.Equal(t, nn.KindBias, p.Kind())
+		}
+		if SelectLayers(1)(p) {
+			lastLayer++
+			assert.Equal(t, 1, p.Layer())
+		}
+	}
+
+	// One bias per neuron; the last layer has a single neuron with two weights & a bias.
+	assert.Equal(t, 3, biases)
+	assert.Equal(t, 3, lastLayer)
+	assert.False(t, SelectKind()(mlp.Parameters()[0]))
+}
+
+func TestNewParamGroups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		groups      []ParamGroup
+		weightDecay float64
+		expectedErr error
+	}{
+		{
+			name:   "valid",
+			groups: []ParamGroup{{Name: "bias", Selector: SelectKind(nn.KindBias)}},
+		},
+		{
+			name:        "no_selector",
+			groups:      []ParamGroup{{Name: "bias"}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "negative_learning_rate_scale",
+			groups:      []ParamGroup{{Selector: SelectKind(nn.KindBias), LearningRateScale: -1}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:   "frozen",
+			groups: []ParamGroup{{Selector: SelectKind(nn.KindBias), Frozen: true}},
+		},
+		{
+			name:        "frozen_with_learning_rate_scale",
+			groups:      []ParamGroup{{Selector: SelectKind(nn.KindBias), Frozen: true, LearningRateScale: 2}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "negative_group_weight_decay",
+			groups:      []ParamGroup{{Selector: SelectKind(nn.KindBias), WeightDecay: WeightDecay(-1)}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_group_weight_decay",
+			groups:      []ParamGroup{{Selector: SelectKind(nn.KindBias), WeightDecay: WeightDecay(math.NaN())}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_learning_rate_scale",
+			groups:      []ParamGroup{{Selector: SelectKind(nn.KindBias), LearningRateScale: math.NaN()}},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "negative_weight_decay",
+			weightDecay: -1,
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_weight_decay",
+			weightDecay: math.NaN(),
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "infinite_weight_decay",
+			weightDecay: math.Inf(1),
+			expectedErr: ErrInvalidHyperparameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newParamGroups(tt.groups, tt.weightDecay)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSGDParamGroups(t *testing.T) {
+	t.Parallel()
+
+	mlp := nn.NewMLPFromConfig(nn.NeuralNetworkConfig{InputShape: 2, Shape: []int{2, 1}, Seed: 1})
+	params := mlp.Parameters()
+
+	sgd, err := NewSGD(SGDConfig{
+		LearningRate: 0.1,
+		WeightDecay:  0.5,
+		Groups: []ParamGroup{
+			{Name: "bias", Selector: SelectKind(nn.KindBias), WeightDecay: WeightDecay(0)},
+			// The head inherits the weight decay of the optimizer.
+			{Name: "head", Selector: SelectLayers(1), LearningRateScale: 10},
+		},
+	})
+	require.NoError(t, err)
+
+	var before = make([]float64, len(params))
+	for i, p := range params {
+		before[i] = p.Float64()
+	}
+
+	// Every gradient is zero, so params only move by weight decay.
+	sgd.Step(params)
+
+	for i, p := range params {
+		var expected float64
+		switch {
+		case p.Kind() == nn.KindBias:
+			// First match wins, so the biases of the head are also excluded from weight decay.
+			expected = before[i]
+		case p.Layer() == 1:
+			expected = before[i] - 0.1*10*0.5*before[i]
+		default:
+			expected = before[i] - 0.1*0.5*before[i]
+		}
+
+		assert.InDelta(t, expected, p.Float64(), 1e-12, "param %d", i)
+	}
+}
+
+func TestFrozenParamGroup(t *testing.T) {
+	t.Parallel()
+
+	mlp := nn.NewMLPFromConfig(nn.NeuralNetworkConfig{InputShape: 2, Shape: []int{2, 1}, Seed: 1})
+	params := mlp.Parameters()
+
+	adamW, err := NewAdamW(AdamConfig{
+		LearningRate: 0.1,
+		WeightDecay:  0.5,
+		Groups:       []ParamGroup{{Name: "body", Selector: SelectLayers(0), Frozen: true}},
+	})
+	require.NoError(t, err)
+
+	var before = make([]float64, len(params))
+	for i, p := range params {
+		before[i] = p.Float64()
+		p.SetGrad(1)
+	}
+
+	adamW.Step(params)
+
+	for i, p := range params {
+		if p.Layer() == 0 {
+			assert.Equal(t, before[i], p.Float64(), "param %d", i)
+			continue
+		}
+
+		assert.NotEqual(t, before[i], p.Float64(), "param %d", i)
+	}
+}
@@ -1 +1,44 @@
 package optimizer
+
+import (
+	"errors"
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+var (
+	ErrInvalidLearningRate   = errors.New("invalid learning rate")
+	ErrInvalidHyperparameter = errors.New("invalid hyperparameter")
+)
+
+func zeroGrad(params []*nn.Value) {
+	for _, p := range params {
+		p.ZeroGrad()
+	}
+}
+
+func floatOrDefault(f, d float64) float64 {
+	if f != 0 {
+		return f
+	}
+
+	return d
+}
+
+// checkLearningRate validates the learning rate of a config, which must be finite & positive. A zero rate is rejected
+// rather than replaced by a default, since an optimizer which never moves its params is almost always a mistake;
+// callers wanting the usual rate pass the default constant of the optimizer, e.g DefaultAdamLearningRate.
+func checkLearningRate(rate float64) error {
+	if !validLearningRate(rate) || rate == 0 {
+		return fmt.Errorf("learning rate %f must be finite & positive: %w", rate, ErrInvalidLearningRate)
+	}
+
+	return nil
+}
+
+// validLearningRate reports whether the rate can be used as a learning rate, i.e it is finite & not negative.
+// Constructors reject an invalid rate, whilst SetLearningRate ignores it & keeps the current rate.
+func validLearningRate(rate float64) bool {
+	return rate >= 0 && !math.IsInf(rate, 1)
+}
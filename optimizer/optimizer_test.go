@@ -0,0 +1,56 @@
+package optimizer
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLearningRateIgnoresInvalidRates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		newOptimizer func() (nn.Optimizer, error)
+	}{
+		{
+			name:         "sgd",
+			newOptimizer: func() (nn.Optimizer, error) { return NewSGD(SGDConfig{LearningRate: 0.1}) },
+		},
+		{
+			name:         "adam",
+			newOptimizer: func() (nn.Optimizer, error) { return NewAdam(AdamConfig{LearningRate: 0.1}) },
+		},
+		{
+			name:         "rmsprop",
+			newOptimizer: func() (nn.Optimizer, error) { return NewRMSProp(RMSPropConfig{LearningRate: 0.1}) },
+		},
+		{
+			name:         "adagrad",
+			newOptimizer: func() (nn.Optimizer, error) { return NewAdagrad(AdagradConfig{LearningRate: 0.1}) },
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, err := tt.newOptimizer()
+			require.NoError(t, err)
+
+			for _, rate := range []float64{-0.1, math.NaN(), math.Inf(1)} {
+				o.SetLearningRate(rate)
+				assert.Equal(t, 0.1, o.LearningRate())
+			}
+
+			// Zero is a valid rate, e.g a schedule annealing to zero.
+			o.SetLearningRate(0)
+			assert.Zero(t, o.LearningRate())
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package optimizer
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+	"sync"
+)
+
+const (
+	// DefaultRMSPropLearningRate is the usual learning rate of RMSProp, for use in RMSPropConfig.
+	DefaultRMSPropLearningRate = 0.01
+	defaultRMSPropAlpha        = 0.99
+	defaultRMSPropEpsilon      = 1e-8
+
+	rmsPropStateName     = "rmsprop"
+	squareAverageBuffer  = "square_average"
+	gradAverageBuffer    = "grad_average"
+	momentumBufferBuffer = "momentum_buffer"
+)
+
+var (
+	_ nn.Optimizer               = new(RMSPropOptimizer)
+	_ nn.OptimizerStateMarshaler = new(RMSPropOptimizer)
+)
+
+type RMSPropConfig struct {
+	// LearningRate must be positive; DefaultRMSPropLearningRate of 0.01 is a common choice.
+	LearningRate float64
+	// Alpha is the decay rate of the moving average of squared gradients, within (0, 1); defaults to 0.99 if zero.
+	Alpha float64
+	// Epsilon is added to the denominator for numerical stability; defaults to 1e-8 if zero.
+	Epsilon float64
+	// Momentum is the coefficient of the momentum buffer; zero disables momentum.
+	Momentum float64
+	// Centered normalizes the gradient by an estimate of its variance, rather than its uncentered second moment.
+	Centered bool
+	// WeightDecay is the coefficient of the L2 penalty added to the gradient; zero disables weight decay.
+	WeightDecay float64
+	// Groups override the learning rate & weight decay of the params they select.
+	Groups []ParamGroup
+}
+
+func NewRMSProp(cfg RMSPropConfig) (*RMSPropOptimizer, error) {
+	if err := checkLearningRate(cfg.LearningRate); err != nil {
+		return nil, err
+	}
+
+	alpha := floatOrDefault(cfg.Alpha, defaultRMSPropAlpha)
+	epsilon := floatOrDefault(cfg.Epsilon, defaultRMSPropEpsilon)
+
+	switch {
+	case !(alpha > 0 && alpha < 1):
+		return nil, fmt.Errorf("alpha %f must be within (0, 1): %w", alpha, ErrInvalidHyperparameter)
+	case !(epsilon >= 0) || math.IsInf(epsilon, 1):
+		return nil, fmt.Errorf("epsilon %f must be finite & not negative: %w", epsilon, ErrInvalidHyperparameter)
+	case !(cfg.Momentum >= 0) || math.IsInf(cfg.Momentum, 1):
+		return nil, fmt.Errorf("momentum %f must be finite & not negative: %w", cfg.Momentum, ErrInvalidHyperparameter)
+	}
+
+	groups, err := newParamGroups(cfg.Groups, cfg.WeightDecay)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RMSPropOptimizer{
+		learningRate:   cfg.LearningRate,
+		alpha:          alpha,
+		epsilon:        epsilon,
+		momentum:       cfg.Momentum,
+		centered:       cfg.Centered,
+		groups:         groups,
+		squareAverages: make(map[*nn.Value]float64),
+		gradAverages:   make(map[*nn.Value]float64),
+		momentumBuffer: make(map[*nn.Value]float64),
+	}, nil
+}
+
+// RMSPropOptimizer divides the gradient by a moving average of its recent magnitude:
+//
+//	square_average = alpha * square_average + (1 - alpha) * grad ** 2
+//	param          = param - learning_rate * grad / (sqrt(square_average) + epsilon)
+//
+// The centered variant subtracts the squared moving average of the gradient from square_average.
+type RMSPropOptimizer struct {
+	learningRate   float64
+	alpha          float64
+	epsilon        float64
+	momentum       float64
+	centered       bool
+	groups         paramGroups
+	squareAverages map[*nn.Value]float64
+	gradAverages   map[*nn.Value]float64
+	momentumBuffer map[*nn.Value]float64
+	mu             sync.RWMutex
+}
+
+func (r *RMSPropOptimizer) Step(params []*nn.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range params {
+		h := r.groups.resolve(p)
+		grad := p.Grad() + h.weightDecay*p.Float64()
+
+		squareAverage := r.alpha*r.squareAverages[p] + (1-r.alpha)*grad*grad
+		r.squareAverages[p] = squareAverage
+
+		variance := squareAverage
+		if r.centered {
+			gradAverage := r.alpha*r.gradAverages[p] + (1-r.alpha)*grad
+			r.gradAverages[p] = gradAverage
+
+			variance -= gradAverage * gradAverage
+		}
+
+		normalized := grad / (math.Sqrt(math.Max(variance, 0)) + r.epsilon)
+
+		if r.momentum > 0 {
+			buffer := r.momentum*r.momentumBuffer[p] + normalized
+			r.momentumBuffer[p] = buffer
+
+			normalized = buffer
+		}
+
+		p.ApplyUpdate(-r.learningRate * h.learningRateScale * normalized)
+	}
+}
+
+func (r *RMSPropOptimizer) ZeroGrad(params []*nn.Value) {
+	zeroGrad(params)
+}
+
+func (r *RMSPropOptimizer) LearningRate() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.learningRate
+}
+
+func (r *RMSPropOptimizer) SetLearningRate(rate float64) {
+	if !validLearningRate(rate) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.learningRate = rate
+}
+
+// MarshalState serializes the moving averages & momentum buffer of the optimizer, keyed by the names of the params.
+func (r *RMSPropOptimizer) MarshalState(params []nn.NamedParameter) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return marshalState(rmsPropStateName, r.learningRate, 0, map[string]map[*nn.Value]float64{
+		squareAverageBuffer:  r.squareAverages,
+		gradAverageBuffer:    r.gradAverages,
+		momentumBufferBuffer: r.momentumBuffer,
+	}, params)
+}
+
+// UnmarshalState replaces the learning rate, moving averages & momentum buffer of the optimizer with those
+// previously serialized by MarshalState.
+func (r *RMSPropOptimizer) UnmarshalState(data []byte, params []nn.NamedParameter) error {
+	decoded, err := unmarshalState(data, rmsPropStateName, params)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.learningRate = decoded.learningRate
+	r.squareAverages = decoded.buffer(squareAverageBuffer)
+	r.gradAverages = decoded.buffer(gradAverageBuffer)
+	r.momentumBuffer = decoded.buffer(momentumBufferBuffer)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package optimizer
+
+import (
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRMSPropOptimizerStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cfg         RMSPropConfig
+		expected    []float64
+		expectedErr error
+	}{
+		{
+			name:     "plain",
+			cfg:      RMSPropConfig{LearningRate: 0.1, Alpha: 0.9},
+			expected: []float64{0.683772239, 0.454356508},
+		},
+		{
+			name:     "centered",
+			cfg:      RMSPropConfig{LearningRate: 0.1, Alpha: 0.9, Centered: true},
+			expected: []float64{0.666666672, 0.411760305},
+		},
+		{
+			name:     "momentum",
+			cfg:      RMSPropConfig{LearningRate: 0.1, Alpha: 0.9, Momentum: 0.9},
+			expected: []float64{0.683772239, 0.169751523},
+		},
+		{
+			name:        "alpha_out_of_range",
+			cfg:         RMSPropConfig{LearningRate: DefaultRMSPropLearningRate, Alpha: 1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "negative_momentum",
+			cfg:         RMSPropConfig{LearningRate: DefaultRMSPropLearningRate, Momentum: -1},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_alpha",
+			cfg:         RMSPropConfig{LearningRate: DefaultRMSPropLearningRate, Alpha: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rmsprop, err := NewRMSProp(tt.cfg)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			p := nn.NewValueFromScalar(nn.Float64Backend.FromFloat64(1.0), nn.OperationNOOP, nn.KindWeight, "p")
+			for _, expected := range tt.expected {
+				setGrad(p, 2.0)
+				rmsprop.Step([]*nn.Value{p})
+
+				assert.InDelta(t, expected, p.Float64(), 1e-8)
+			}
+		})
+	}
+}
+
+func TestRMSPropOptimizerTrainsNeuralNetwork(t *testing.T) {
+	t.Parallel()
+
+	rmsprop, err := NewRMSProp(RMSPropConfig{LearningRate: 0.02})
+	require.NoError(t, err)
+
+	assertTrainsLinearRegression(t, rmsprop)
+}
@@ -1,15 +1,175 @@
 package optimizer
 
 import (
+	"fmt"
 	"grad2go/nn"
+	"math"
+	"sync"
+)
+
+const (
+	// DefaultSGDLearningRate is the usual learning rate of SGD, for use in SGDConfig & by the SGD function.
+	DefaultSGDLearningRate = 0.01
 
-	"github.com/shopspring/decimal"
+	sgdStateName   = "sgd"
+	velocityBuffer = "velocity"
 )
 
-const defaultLearningRate = 0.01
+var (
+	_ nn.Optimizer               = new(SGDOptimizer)
+	_ nn.OptimizerStateMarshaler = new(SGDOptimizer)
+)
 
+// SGD applies a single step of gradient descent at the default learning rate. It is kept for existing callers
+// & can be passed to a network via nn.OptimizerFunc; prefer NewSGD, which has a configurable learning rate.
 func SGD(values []*nn.Value) {
 	for _, v := range values {
-		v.ApplyDescent(decimal.NewFromFloat(-defaultLearningRate))
+		v.ApplyDescent(DefaultSGDLearningRate)
+	}
+}
+
+type SGDConfig struct {
+	// LearningRate must be positive; DefaultSGDLearningRate of 0.01 is a common choice.
+	LearningRate float64
+	// Momentum is the coefficient of the velocity carried between steps; zero disables momentum.
+	Momentum float64
+	// Dampening scales down the contribution of the current gradient to the velocity; must be within [0, 1].
+	Dampening float64
+	// Nesterov enables Nesterov accelerated gradient; it requires a positive momentum & zero dampening.
+	Nesterov bool
+	// WeightDecay is the coefficient of the L2 penalty added to the gradient; zero disables weight decay.
+	WeightDecay float64
+	// Groups override the learning rate & weight decay of the params they select.
+	Groups []ParamGroup
+}
+
+func NewSGD(cfg SGDConfig) (*SGDOptimizer, error) {
+	if err := checkLearningRate(cfg.LearningRate); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !(cfg.Momentum >= 0) || math.IsInf(cfg.Momentum, 1):
+		return nil, fmt.Errorf("momentum %f must be finite & not negative: %w", cfg.Momentum, ErrInvalidHyperparameter)
+	case !(cfg.Dampening >= 0 && cfg.Dampening <= 1):
+		return nil, fmt.Errorf("dampening %f must be within [0, 1]: %w", cfg.Dampening, ErrInvalidHyperparameter)
+	case cfg.Nesterov && (cfg.Momentum == 0 || cfg.Dampening != 0):
+		return nil, fmt.Errorf("nesterov requires positive momentum & zero dampening: %w", ErrInvalidHyperparameter)
+	}
+
+	groups, err := newParamGroups(cfg.Groups, cfg.WeightDecay)
+	if err != nil {
+		return nil, err
 	}
+
+	return &SGDOptimizer{
+		learningRate: cfg.LearningRate,
+		momentum:     cfg.Momentum,
+		dampening:    cfg.Dampening,
+		nesterov:     cfg.Nesterov,
+		groups:       groups,
+		velocities:   make(map[*nn.Value]float64),
+	}, nil
+}
+
+// SGDOptimizer is stochastic gradient descent, i.e param = param - learning_rate * grad, with optional (Nesterov)
+// momentum:
+//
+//	velocity = momentum * velocity + (1 - dampening) * grad
+//	param    = param - learning_rate * velocity                      (classical)
+//	param    = param - learning_rate * (grad + momentum * velocity)  (nesterov)
+type SGDOptimizer struct {
+	learningRate float64
+	momentum     float64
+	dampening    float64
+	nesterov     bool
+	groups       paramGroups
+	velocities   map[*nn.Value]float64
+	mu           sync.RWMutex
+}
+
+func (s *SGDOptimizer) Step(params []*nn.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range params {
+		h := s.groups.resolve(p)
+		learningRate := s.learningRate * h.learningRateScale
+
+		if s.momentum == 0 && h.weightDecay == 0 {
+			p.ApplyDescent(learningRate)
+			continue
+		}
+
+		grad := p.Grad() + h.weightDecay*p.Float64()
+		if s.momentum == 0 {
+			p.ApplyUpdate(-learningRate * grad)
+			continue
+		}
+
+		velocity, ok := s.velocities[p]
+		if !ok {
+			// The velocity is seeded with the first gradient, undampened.
+			velocity = grad
+		} else {
+			velocity = s.momentum*velocity + (1-s.dampening)*grad
+		}
+		s.velocities[p] = velocity
+
+		var update = velocity
+		if s.nesterov {
+			update = grad + s.momentum*velocity
+		}
+
+		p.ApplyUpdate(-learningRate * update)
+	}
+}
+
+func (s *SGDOptimizer) ZeroGrad(params []*nn.Value) {
+	zeroGrad(params)
+}
+
+func (s *SGDOptimizer) LearningRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.learningRate
+}
+
+func (s *SGDOptimizer) SetLearningRate(rate float64) {
+	if !validLearningRate(rate) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.learningRate = rate
+}
+
+// MarshalState serializes the velocities of the optimizer, keyed by the names of the params.
+func (s *SGDOptimizer) MarshalState(params []nn.NamedParameter) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return marshalState(sgdStateName, s.learningRate, 0, map[string]map[*nn.Value]float64{
+		velocityBuffer: s.velocities,
+	}, params)
+}
+
+// UnmarshalState replaces the learning rate & velocities of the optimizer with those previously serialized by
+// MarshalState.
+func (s *SGDOptimizer) UnmarshalState(data []byte, params []nn.NamedParameter) error {
+	decoded, err := unmarshalState(data, sgdStateName, params)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.learningRate = decoded.learningRate
+	s.velocities = decoded.buffer(velocityBuffer)
+
+	return nil
 }
@@ -0,0 +1,338 @@
+package optimizer
+
+import (
+	"grad2go/loss"
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newParamWithGrad returns a leaf holding data whose gradient is grad.
+func newParamWithGrad(backend nn.Backend, data, grad float64) *nn.Value {
+	p := nn.NewValueFromScalar(backend.FromFloat64(data), nn.OperationNOOP, nn.KindWeight, "p")
+	setGrad(p, grad)
+
+	return p
+}
+
+// setGrad replaces the gradient of p with grad via a backward pass through p * grad.
+func setGrad(p *nn.Value, grad float64) {
+	p.ZeroGrad()
+
+	c := nn.NewValueFromScalar(p.Backend().FromFloat64(grad), nn.OperationNOOP, nn.KindValue, "c")
+	p.Mul(c).Backward()
+}
+
+func TestNewSGD(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		cfg                  SGDConfig
+		expectedLearningRate float64
+		expectedErr          error
+	}{
+		{
+			name:        "zero",
+			expectedErr: ErrInvalidLearningRate,
+		},
+		{
+			name:                 "configured",
+			cfg:                  SGDConfig{LearningRate: 0.5},
+			expectedLearningRate: 0.5,
+		},
+		{
+			name:        "negative",
+			cfg:         SGDConfig{LearningRate: -0.5},
+			expectedErr: ErrInvalidLearningRate,
+		},
+		{
+			name:        "nan",
+			cfg:         SGDConfig{LearningRate: math.NaN()},
+			expectedErr: ErrInvalidLearningRate,
+		},
+		{
+			name:                 "momentum",
+			cfg:                  SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9, Nesterov: true},
+			expectedLearningRate: DefaultSGDLearningRate,
+		},
+		{
+			name:        "negative_momentum",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: -0.9},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_momentum",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "infinite_momentum",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: math.Inf(1)},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nan_dampening",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9, Dampening: math.NaN()},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "dampening_out_of_range",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9, Dampening: 1.5},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nesterov_without_momentum",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Nesterov: true},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+		{
+			name:        "nesterov_with_dampening",
+			cfg:         SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9, Dampening: 0.1, Nesterov: true},
+			expectedErr: ErrInvalidHyperparameter,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sgd, err := NewSGD(tt.cfg)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLearningRate, sgd.LearningRate())
+		})
+	}
+}
+
+func TestSGDOptimizerStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		backend nn.Backend
+	}{
+		{
+			name:    "float64",
+			backend: nn.Float64Backend,
+		},
+		{
+			name:    "decimal",
+			backend: nn.DecimalBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sgd, err := NewSGD(SGDConfig{LearningRate: 0.1})
+			require.NoError(t, err)
+
+			p := newParamWithGrad(tt.backend, 1.0, 3.0)
+			sgd.Step([]*nn.Value{p})
+
+			assert.InDelta(t, 0.7, p.Float64(), 1e-12)
+			assert.Equal(t, tt.backend, p.Backend())
+
+			sgd.SetLearningRate(0.2)
+			assert.Equal(t, 0.2, sgd.LearningRate())
+
+			sgd.ZeroGrad([]*nn.Value{p})
+			assert.Zero(t, p.Grad())
+		})
+	}
+}
+
+func TestSGDOptimizerMomentum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cfg      SGDConfig
+		expected []float64
+	}{
+		{
+			name:     "classical",
+			cfg:      SGDConfig{LearningRate: 0.1, Momentum: 0.9},
+			expected: []float64{0.9, 0.71, 0.439},
+		},
+		{
+			name:     "dampened",
+			cfg:      SGDConfig{LearningRate: 0.1, Momentum: 0.9, Dampening: 0.5},
+			expected: []float64{0.9, 0.76, 0.584},
+		},
+		{
+			name:     "nesterov",
+			cfg:      SGDConfig{LearningRate: 0.1, Momentum: 0.9, Nesterov: true},
+			expected: []float64{0.81, 0.539, 0.1951},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sgd, err := NewSGD(tt.cfg)
+			require.NoError(t, err)
+
+			// A constant gradient of one on every step.
+			p := nn.NewValueFromScalar(nn.Float64Backend.FromFloat64(1.0), nn.OperationNOOP, nn.KindWeight, "p")
+			for _, expected := range tt.expected {
+				setGrad(p, 1.0)
+				sgd.Step([]*nn.Value{p})
+
+				assert.InDelta(t, expected, p.Float64(), 1e-12)
+			}
+		})
+	}
+}
+
+func TestSGDFuncAdapter(t *testing.T) {
+	t.Parallel()
+
+	var optimizer nn.Optimizer = nn.OptimizerFunc(SGD)
+
+	p := newParamWithGrad(nn.Float64Backend, 1.0, 2.0)
+	optimizer.Step([]*nn.Value{p})
+
+	assert.InDelta(t, 1.0-DefaultSGDLearningRate*2.0, p.Float64(), 1e-12)
+	assert.Zero(t, optimizer.LearningRate())
+}
+
+func TestSGDGradientAccumulation(t *testing.T) {
+	t.Parallel()
+
+	newNetwork := func(accumulationSteps int, optimizer nn.Optimizer) *nn.NeuralNetwork {
+		return nn.NewNeuralNetwork(nn.NeuralNetworkConfig{
+			InputShape:        2,
+			Shape:             []int{3, 1},
+			AccumulationSteps: accumulationSteps,
+			Seed:              1,
+		}, optimizer, loss.MeanSquaredError)
+	}
+
+	step := func(net *nn.NeuralNetwork) {
+		input := []*nn.Value{
+			nn.NewValue(decimal.NewFromFloat(0.5), nn.OperationNOOP, nn.KindInput, "a"),
+			nn.NewValue(decimal.NewFromFloat(-1), nn.OperationNOOP, nn.KindInput, "b"),
+		}
+		expectation := []*nn.Value{nn.NewValue(decimal.NewFromFloat(2), nn.OperationNOOP, nn.KindInput, "y")}
+
+		_, err := net.Step(input, expectation)
+		require.NoError(t, err)
+	}
+
+	var updates int
+	accumulated := newNetwork(3, nn.OptimizerFunc(func(params []*nn.Value) {
+		updates++
+		SGD(params)
+	}))
+
+	var initial []float64
+	for _, p := range accumulated.Parameters() {
+		initial = append(initial, p.Float64())
+	}
+
+	// The parameters don't move until the third step, so each step sees the same gradients.
+	for i := 0; i < 2; i++ {
+		step(accumulated)
+
+		for j, p := range accumulated.Parameters() {
+			assert.Equal(t, initial[j], p.Float64())
+		}
+	}
+	step(accumulated)
+	assert.Equal(t, 1, updates)
+
+	// Three accumulated steps apply the sum of their gradients once, i.e a single step at three times the rate.
+	sgd, err := NewSGD(SGDConfig{LearningRate: 3 * DefaultSGDLearningRate})
+	require.NoError(t, err)
+
+	single := newNetwork(0, sgd)
+	step(single)
+
+	expected := single.Parameters()
+	for i, p := range accumulated.Parameters() {
+		assert.InDelta(t, expected[i].Float64(), p.Float64(), 1e-12)
+	}
+}
+
+func TestSGDOptimizerTrainsNeuralNetwork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  SGDConfig
+	}{
+		{
+			name: "plain",
+			cfg:  SGDConfig{LearningRate: 0.05},
+		},
+		{
+			name: "momentum",
+			cfg:  SGDConfig{LearningRate: 0.02, Momentum: 0.8},
+		},
+		{
+			name: "nesterov",
+			cfg:  SGDConfig{LearningRate: 0.02, Momentum: 0.8, Nesterov: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sgd, err := NewSGD(tt.cfg)
+			require.NoError(t, err)
+
+			assertTrainsLinearRegression(t, sgd)
+		})
+	}
+}
+
+// assertTrainsLinearRegression asserts that the optimizer can fit y = 2x - 1 with a single linear neuron.
+func assertTrainsLinearRegression(t *testing.T, optimizer nn.Optimizer) {
+	t.Helper()
+
+	net := nn.NewNeuralNetwork(nn.NeuralNetworkConfig{
+		InputShape: 1,
+		Shape:      []int{1},
+		Seed:       1,
+	}, optimizer, loss.MeanSquaredError)
+
+	// Learn y = 2x - 1.
+	var first, last float64
+	for i := 0; i < 200; i++ {
+		x := float64(i%5)/2 - 1
+		input := []*nn.Value{nn.NewValue(decimal.NewFromFloat(x), nn.OperationNOOP, nn.KindInput, "x")}
+		expectation := []*nn.Value{nn.NewValue(decimal.NewFromFloat(2*x-1), nn.OperationNOOP, nn.KindInput, "y")}
+
+		loss, err := net.Step(input, expectation)
+		require.NoError(t, err)
+
+		if i == 0 {
+			first = loss.Float64()
+		}
+		last = loss.Float64()
+	}
+
+	assert.Less(t, last, first)
+	assert.Less(t, last, 1e-3)
+}
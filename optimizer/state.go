@@ -0,0 +1,156 @@
+package optimizer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grad2go/nn"
+	"math"
+	"strconv"
+)
+
+var ErrInvalidState = errors.New("invalid optimizer state")
+
+// state is the serialized form shared by every optimizer. Buffers maps the name of each per parameter buffer, e.g
+// velocity, to the value held for each parameter, keyed by the parameter's name rather than its pointer.
+type state struct {
+	Optimizer    string                           `json:"optimizer"`
+	LearningRate stateFloat                       `json:"learning_rate"`
+	Steps        int                              `json:"steps"`
+	Buffers      map[string]map[string]stateFloat `json:"buffers"`
+}
+
+// stateFloat is a float64 which survives a JSON round trip even if it isn't finite, e.g a moment which has
+// overflowed: finite values are encoded as numbers, whereas NaN & infinities, which JSON numbers cannot hold, are
+// encoded as the strings "NaN", "+Inf" & "-Inf".
+type stateFloat float64
+
+func (f stateFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return json.Marshal(strconv.FormatFloat(float64(f), 'g', -1, 64))
+	}
+
+	return json.Marshal(float64(f))
+}
+
+func (f *stateFloat) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var number float64
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+
+		*f = stateFloat(number)
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(math.IsNaN(number) || math.IsInf(number, 0)) {
+		return fmt.Errorf("%q is neither a number nor NaN or an infinity: %w", s, ErrInvalidState)
+	}
+
+	*f = stateFloat(number)
+	return nil
+}
+
+func marshalState(
+	optimizer string,
+	learningRate float64,
+	steps int,
+	buffers map[string]map[*nn.Value]float64,
+	params []nn.NamedParameter,
+) ([]byte, error) {
+	var names = make(map[*nn.Value]string, len(params))
+	for _, p := range params {
+		names[p.Value] = p.Name
+	}
+
+	s := state{
+		Optimizer:    optimizer,
+		LearningRate: stateFloat(learningRate),
+		Steps:        steps,
+		Buffers:      make(map[string]map[string]stateFloat, len(buffers)),
+	}
+
+	for bufferName, buffer := range buffers {
+		var encoded = make(map[string]stateFloat, len(buffer))
+		for p, v := range buffer {
+			name, ok := names[p]
+			if !ok {
+				return nil, fmt.Errorf("%s holds state for an unnamed parameter: %w", bufferName, ErrInvalidState)
+			}
+
+			encoded[name] = stateFloat(v)
+		}
+
+		s.Buffers[bufferName] = encoded
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s state: %w", optimizer, err)
+	}
+
+	return data, nil
+}
+
+// decodedState is a state whose buffers have been mapped back onto parameters.
+type decodedState struct {
+	learningRate float64
+	steps        int
+	buffers      map[string]map[*nn.Value]float64
+}
+
+// buffer returns the named buffer, which is empty if the state held no such buffer.
+func (d *decodedState) buffer(name string) map[*nn.Value]float64 {
+	if buffer, ok := d.buffers[name]; ok {
+		return buffer
+	}
+
+	return make(map[*nn.Value]float64)
+}
+
+func unmarshalState(data []byte, optimizer string, params []nn.NamedParameter) (*decodedState, error) {
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s state: %w", optimizer, err)
+	}
+
+	if s.Optimizer != optimizer {
+		return nil, fmt.Errorf("state of %q cannot be restored into %q: %w", s.Optimizer, optimizer, ErrInvalidState)
+	}
+
+	if !validLearningRate(float64(s.LearningRate)) || s.Steps < 0 {
+		return nil, fmt.Errorf(
+			"invalid learning rate %f or negative steps: %w", float64(s.LearningRate), ErrInvalidState,
+		)
+	}
+
+	var values = make(map[string]*nn.Value, len(params))
+	for _, p := range params {
+		values[p.Name] = p.Value
+	}
+
+	decoded := &decodedState{
+		learningRate: float64(s.LearningRate),
+		steps:        s.Steps,
+		buffers:      make(map[string]map[*nn.Value]float64, len(s.Buffers)),
+	}
+
+	for bufferName, buffer := range s.Buffers {
+		var restored = make(map[*nn.Value]float64, len(buffer))
+		for name, v := range buffer {
+			p, ok := values[name]
+			if !ok {
+				return nil, fmt.Errorf("%s holds state for unknown parameter %q: %w", bufferName, name, ErrInvalidState)
+			}
+
+			restored[p] = float64(v)
+		}
+
+		decoded.buffers[bufferName] = restored
+	}
+
+	return decoded, nil
+}
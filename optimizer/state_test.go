@@ -0,0 +1,182 @@
+package optimizer
+
+import (
+	"grad2go/loss"
+	"grad2go/nn"
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStateTestNetwork(t *testing.T, shape []int, optimizer nn.Optimizer) *nn.NeuralNetwork {
+	t.Helper()
+
+	return nn.NewNeuralNetwork(nn.NeuralNetworkConfig{
+		InputShape: 2,
+		Shape:      shape,
+		Seed:       1,
+	}, optimizer, loss.MeanSquaredError)
+}
+
+func trainSteps(t *testing.T, net *nn.NeuralNetwork, from, to int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		a, b := float64(i%3)-1, float64(i%4)/2
+		input := []*nn.Value{
+			nn.NewValue(decimal.NewFromFloat(a), nn.OperationNOOP, nn.KindInput, "a"),
+			nn.NewValue(decimal.NewFromFloat(b), nn.OperationNOOP, nn.KindInput, "b"),
+		}
+		expectation := []*nn.Value{nn.NewValue(decimal.NewFromFloat(a-2*b), nn.OperationNOOP, nn.KindInput, "y")}
+
+		_, err := net.Step(input, expectation)
+		require.NoError(t, err)
+	}
+}
+
+func TestOptimizerStateResume(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		newOptimizer func() (nn.Optimizer, error)
+	}{
+		{
+			name: "sgd",
+			newOptimizer: func() (nn.Optimizer, error) {
+				return NewSGD(SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9, Nesterov: true})
+			},
+		},
+		{
+			name:         "adam",
+			newOptimizer: func() (nn.Optimizer, error) { return NewAdam(AdamConfig{LearningRate: 0.01}) },
+		},
+		{
+			name: "adamw",
+			newOptimizer: func() (nn.Optimizer, error) {
+				return NewAdamW(AdamConfig{LearningRate: DefaultAdamLearningRate, WeightDecay: 0.1})
+			},
+		},
+		{
+			name: "rmsprop",
+			newOptimizer: func() (nn.Optimizer, error) {
+				return NewRMSProp(RMSPropConfig{LearningRate: DefaultRMSPropLearningRate, Momentum: 0.5, Centered: true})
+			},
+		},
+		{
+			name: "adagrad",
+			newOptimizer: func() (nn.Optimizer, error) {
+				return NewAdagrad(AdagradConfig{LearningRate: DefaultAdagradLearningRate, LearningRateDecay: 0.1})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			optimizer, err := tt.newOptimizer()
+			require.NoError(t, err)
+			original := newStateTestNetwork(t, []int{3, 1}, optimizer)
+			trainSteps(t, original, 0, 10)
+
+			data, err := original.MarshalOptimizerState()
+			require.NoError(t, err)
+
+			// Resume into a fresh network & optimizer, restoring the params by name.
+			optimizer, err = tt.newOptimizer()
+			require.NoError(t, err)
+			resumed := newStateTestNetwork(t, []int{3, 1}, optimizer)
+
+			originalParams := original.NamedParameters()
+			for i, p := range resumed.NamedParameters() {
+				require.Equal(t, originalParams[i].Name, p.Name)
+				p.Value.SetFloat64(originalParams[i].Value.Float64())
+			}
+			require.NoError(t, resumed.UnmarshalOptimizerState(data))
+
+			trainSteps(t, original, 10, 20)
+			trainSteps(t, resumed, 10, 20)
+
+			for i, p := range resumed.NamedParameters() {
+				assert.Equal(t, originalParams[i].Value.Float64(), p.Value.Float64(), p.Name)
+			}
+		})
+	}
+}
+
+func TestOptimizerStateErrors(t *testing.T) {
+	t.Parallel()
+
+	sgd, err := NewSGD(SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9})
+	require.NoError(t, err)
+	net := newStateTestNetwork(t, []int{3, 1}, sgd)
+	trainSteps(t, net, 0, 1)
+
+	data, err := net.MarshalOptimizerState()
+	require.NoError(t, err)
+
+	t.Run("different_optimizer", func(t *testing.T) {
+		t.Parallel()
+
+		adam, err := NewAdam(AdamConfig{LearningRate: DefaultAdamLearningRate})
+		require.NoError(t, err)
+
+		err = newStateTestNetwork(t, []int{3, 1}, adam).UnmarshalOptimizerState(data)
+		assert.ErrorIs(t, err, ErrInvalidState)
+	})
+
+	t.Run("different_shape", func(t *testing.T) {
+		t.Parallel()
+
+		sgd, err := NewSGD(SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9})
+		require.NoError(t, err)
+
+		err = newStateTestNetwork(t, []int{1}, sgd).UnmarshalOptimizerState(data)
+		assert.ErrorIs(t, err, ErrInvalidState)
+	})
+}
+
+func TestOptimizerStateNonFinite(t *testing.T) {
+	t.Parallel()
+
+	params := []nn.NamedParameter{
+		{Name: "nan", Value: nn.NewValue(decimal.Zero, nn.OperationNOOP, nn.KindWeight, "nan")},
+		{Name: "inf", Value: nn.NewValue(decimal.Zero, nn.OperationNOOP, nn.KindWeight, "inf")},
+		{Name: "neg_inf", Value: nn.NewValue(decimal.Zero, nn.OperationNOOP, nn.KindWeight, "neg_inf")},
+		{Name: "finite", Value: nn.NewValue(decimal.Zero, nn.OperationNOOP, nn.KindWeight, "finite")},
+	}
+
+	sgd, err := NewSGD(SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9})
+	require.NoError(t, err)
+	sgd.velocities[params[0].Value] = math.NaN()
+	sgd.velocities[params[1].Value] = math.Inf(1)
+	sgd.velocities[params[2].Value] = math.Inf(-1)
+	sgd.velocities[params[3].Value] = 0.25
+
+	data, err := sgd.MarshalState(params)
+	require.NoError(t, err)
+
+	restored, err := NewSGD(SGDConfig{LearningRate: DefaultSGDLearningRate, Momentum: 0.9})
+	require.NoError(t, err)
+	require.NoError(t, restored.UnmarshalState(data, params))
+
+	assert.True(t, math.IsNaN(restored.velocities[params[0].Value]))
+	assert.Equal(t, math.Inf(1), restored.velocities[params[1].Value])
+	assert.Equal(t, math.Inf(-1), restored.velocities[params[2].Value])
+	assert.Equal(t, 0.25, restored.velocities[params[3].Value])
+
+	t.Run("invalid_string", func(t *testing.T) {
+		t.Parallel()
+
+		err := restored.UnmarshalState(
+			[]byte(`{"optimizer":"sgd","learning_rate":0.01,"buffers":{"velocity":{"finite":"1.5"}}}`), params,
+		)
+		assert.ErrorIs(t, err, ErrInvalidState)
+	})
+}
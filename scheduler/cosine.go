@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+var _ Scheduler = new(CosineAnnealingWarmRestartsScheduler)
+
+type CosineAnnealingWarmRestartsConfig struct {
+	// Period is the number of steps until the first restart; must be positive.
+	Period int
+	// PeriodMultiplier multiplies the period after every restart; defaults to 1 if zero.
+	PeriodMultiplier int
+	// MinLearningRate is the learning rate annealed towards at the end of each period.
+	MinLearningRate float64
+	MetricsConfig
+}
+
+// CosineAnnealingWarmRestartsScheduler anneals the learning rate along a cosine from the base learning rate down
+// to the minimum, restarting from the base learning rate at the end of each period (SGDR):
+//
+//	learning_rate = min + (base - min) * (1 + cos(pi * t_cur / period)) / 2
+type CosineAnnealingWarmRestartsScheduler struct {
+	*scheduled
+}
+
+func NewCosineAnnealingWarmRestarts(
+	optimizer nn.Optimizer,
+	cfg CosineAnnealingWarmRestartsConfig,
+) (*CosineAnnealingWarmRestartsScheduler, error) {
+	base, err := baseLearningRate(optimizer)
+	if err != nil {
+		return nil, err
+	}
+
+	multiplier := cfg.PeriodMultiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+
+	switch {
+	case cfg.Period <= 0:
+		return nil, fmt.Errorf("period %d must be positive: %w", cfg.Period, ErrInvalidConfig)
+	case multiplier < 1:
+		return nil, fmt.Errorf("period multiplier %d must be at least 1: %w", multiplier, ErrInvalidConfig)
+	case !(cfg.MinLearningRate >= 0 && cfg.MinLearningRate <= base):
+		return nil, fmt.Errorf(
+			"min learning rate %f must be within [0, %f]: %w", cfg.MinLearningRate, base, ErrInvalidConfig,
+		)
+	}
+
+	s, err := newScheduled("cosine_annealing_warm_restarts", optimizer, func(step int) float64 {
+		// Walk forward through the periods to find the position within the current one.
+		current, period := step, cfg.Period
+		for current >= period {
+			current -= period
+			period *= multiplier
+		}
+
+		cosine := (1 + math.Cos(math.Pi*float64(current)/float64(period))) / 2
+		return cfg.MinLearningRate + (base-cfg.MinLearningRate)*cosine
+	}, cfg.MetricLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CosineAnnealingWarmRestartsScheduler{s}, nil
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+const defaultStepDecayGamma = 0.1
+
+var (
+	_ Scheduler = new(StepDecayScheduler)
+	_ Scheduler = new(ExponentialScheduler)
+)
+
+type StepDecayConfig struct {
+	// StepSize is the number of steps between each decay; must be positive.
+	StepSize int
+	// Gamma is the factor the learning rate is multiplied by every StepSize steps; defaults to 0.1 if zero.
+	Gamma float64
+	MetricsConfig
+}
+
+// StepDecayScheduler decays the learning rate by gamma every step size steps:
+//
+//	learning_rate = base_learning_rate * gamma ** floor(t / step_size)
+type StepDecayScheduler struct {
+	*scheduled
+}
+
+func NewStepDecay(optimizer nn.Optimizer, cfg StepDecayConfig) (*StepDecayScheduler, error) {
+	base, err := baseLearningRate(optimizer)
+	if err != nil {
+		return nil, err
+	}
+
+	gamma := cfg.Gamma
+	if gamma == 0 {
+		gamma = defaultStepDecayGamma
+	}
+
+	switch {
+	case cfg.StepSize <= 0:
+		return nil, fmt.Errorf("step size %d must be positive: %w", cfg.StepSize, ErrInvalidConfig)
+	case !(gamma > 0 && gamma <= 1):
+		return nil, fmt.Errorf("gamma %f must be within (0, 1]: %w", gamma, ErrInvalidConfig)
+	}
+
+	s, err := newScheduled("step_decay", optimizer, func(step int) float64 {
+		return base * math.Pow(gamma, float64(step/cfg.StepSize))
+	}, cfg.MetricLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StepDecayScheduler{s}, nil
+}
+
+type ExponentialConfig struct {
+	// Gamma is the factor the learning rate is multiplied by every step; must be within (0, 1].
+	Gamma float64
+	MetricsConfig
+}
+
+// ExponentialScheduler decays the learning rate by gamma every step:
+//
+//	learning_rate = base_learning_rate * gamma ** t
+type ExponentialScheduler struct {
+	*scheduled
+}
+
+func NewExponential(optimizer nn.Optimizer, cfg ExponentialConfig) (*ExponentialScheduler, error) {
+	base, err := baseLearningRate(optimizer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !(cfg.Gamma > 0 && cfg.Gamma <= 1) {
+		return nil, fmt.Errorf("gamma %f must be within (0, 1]: %w", cfg.Gamma, ErrInvalidConfig)
+	}
+
+	s, err := newScheduled("exponential", optimizer, func(step int) float64 {
+		return base * math.Pow(cfg.Gamma, float64(step))
+	}, cfg.MetricLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExponentialScheduler{s}, nil
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"fmt"
+	"grad2go/nn"
+	"math"
+	"sync"
+)
+
+const (
+	defaultPlateauFactor    = 0.1
+	defaultPlateauThreshold = 1e-4
+
+	plateauStateName = "reduce_on_plateau"
+)
+
+var _ Scheduler = new(ReduceOnPlateauScheduler)
+
+type ReduceOnPlateauConfig struct {
+	// Factor is the factor the learning rate is multiplied by on a plateau; defaults to 0.1 if zero.
+	Factor float64
+	// Patience is the number of steps without improvement tolerated before the learning rate is reduced.
+	Patience int
+	// Threshold is the relative decrease in loss required to count as an improvement; defaults to 1e-4 if zero.
+	Threshold float64
+	// Cooldown is the number of steps after a reduction during which steps without improvement aren't counted.
+	Cooldown int
+	// MinLearningRate is the floor the learning rate is never reduced below.
+	MinLearningRate float64
+	MetricsConfig
+}
+
+// ReduceOnPlateauScheduler reduces the learning rate by a factor once the loss has stopped improving for more
+// than patience steps.
+type ReduceOnPlateauScheduler struct {
+	optimizer       nn.Optimizer
+	factor          float64
+	patience        int
+	threshold       float64
+	cooldown        int
+	minLearningRate float64
+	labels          []string
+	best            float64
+	badSteps        int
+	cooldownLeft    int
+	mu              sync.Mutex
+}
+
+func NewReduceOnPlateau(optimizer nn.Optimizer, cfg ReduceOnPlateauConfig) (*ReduceOnPlateauScheduler, error) {
+	if _, err := baseLearningRate(optimizer); err != nil {
+		return nil, err
+	}
+
+	factor := cfg.Factor
+	if factor == 0 {
+		factor = defaultPlateauFactor
+	}
+
+	threshold := cfg.Threshold
+	if threshold == 0 {
+		threshold = defaultPlateauThreshold
+	}
+
+	switch {
+	case !(factor > 0 && factor < 1):
+		return nil, fmt.Errorf("factor %f must be within (0, 1): %w", factor, ErrInvalidConfig)
+	case cfg.Patience < 0:
+		return nil, fmt.Errorf("patience %d must not be negative: %w", cfg.Patience, ErrInvalidConfig)
+	case !(threshold >= 0) || math.IsInf(threshold, 1):
+		return nil, fmt.Errorf("threshold %f must be finite & not negative: %w", threshold, ErrInvalidConfig)
+	case cfg.Cooldown < 0:
+		return nil, fmt.Errorf("cooldown %d must not be negative: %w", cfg.Cooldown, ErrInvalidConfig)
+	case !(cfg.MinLearningRate >= 0) || math.IsInf(cfg.MinLearningRate, 1):
+		return nil, fmt.Errorf(
+			"min learning rate %f must be finite & not negative: %w", cfg.MinLearningRate, ErrInvalidConfig,
+		)
+	}
+
+	// Report the initial learning rate, which also checks the metric labels up front.
+	if err := setLearningRate(optimizer, optimizer.LearningRate(), cfg.MetricLabels); err != nil {
+		return nil, err
+	}
+
+	return &ReduceOnPlateauScheduler{
+		optimizer:       optimizer,
+		factor:          factor,
+		patience:        cfg.Patience,
+		threshold:       threshold,
+		cooldown:        cfg.Cooldown,
+		minLearningRate: cfg.MinLearningRate,
+		labels:          cfg.MetricLabels,
+		best:            math.Inf(1),
+	}, nil
+}
+
+// Step records the loss; it must not be nil.
+func (r *ReduceOnPlateauScheduler) Step(loss *nn.Value) error {
+	if loss == nil {
+		return ErrNilLoss
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := loss.Float64()
+	if current < r.best*(1-r.threshold) {
+		r.best = current
+		r.badSteps = 0
+	} else {
+		r.badSteps++
+	}
+
+	if r.cooldownLeft > 0 {
+		r.cooldownLeft--
+		r.badSteps = 0
+	}
+
+	learningRate := r.optimizer.LearningRate()
+	if r.badSteps > r.patience {
+		learningRate = math.Max(learningRate*r.factor, r.minLearningRate)
+		r.cooldownLeft = r.cooldown
+		r.badSteps = 0
+	}
+
+	return setLearningRate(r.optimizer, learningRate, r.labels)
+}
+
+func (r *ReduceOnPlateauScheduler) LearningRate() float64 {
+	return r.optimizer.LearningRate()
+}
+
+func (r *ReduceOnPlateauScheduler) MarshalState() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := state{
+		Scheduler:    plateauStateName,
+		LearningRate: r.optimizer.LearningRate(),
+		BadSteps:     r.badSteps,
+		CooldownLeft: r.cooldownLeft,
+	}
+
+	// JSON cannot hold the infinite best of a scheduler which hasn't seen a loss yet.
+	if !math.IsInf(r.best, 0) {
+		best := r.best
+		s.Best = &best
+	}
+
+	return marshalState(s)
+}
+
+func (r *ReduceOnPlateauScheduler) UnmarshalState(data []byte) error {
+	decoded, err := unmarshalState(data, plateauStateName)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := setLearningRate(r.optimizer, decoded.LearningRate, r.labels); err != nil {
+		return err
+	}
+
+	r.best = math.Inf(1)
+	if decoded.Best != nil {
+		r.best = *decoded.Best
+	}
+	r.badSteps = decoded.BadSteps
+	r.cooldownLeft = decoded.CooldownLeft
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"grad2go/metrics"
+	"grad2go/nn"
+	"sync"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid scheduler config")
+	ErrNilLoss       = errors.New("nil loss")
+	ErrInvalidState  = errors.New("invalid scheduler state")
+)
+
+// Scheduler adjusts the learning rate of an optimizer as training progresses. Step is called once per step or
+// once per epoch, depending on the granularity the schedule is configured in.
+type Scheduler interface {
+	// Step advances the schedule & sets the learning rate of the optimizer for the next step. The loss is that
+	// returned by NeuralNetwork.Step; only schedulers driven by the loss require it & others accept nil.
+	Step(loss *nn.Value) error
+	LearningRate() float64
+	// MarshalState serializes the progress of the schedule, e.g the number of steps taken, together with the
+	// current learning rate.
+	MarshalState() ([]byte, error)
+	// UnmarshalState restores the progress previously serialized by MarshalState into a scheduler of the same kind
+	// & sets the restored learning rate on the optimizer.
+	UnmarshalState(data []byte) error
+}
+
+// MetricsConfig is embedded by the config of every scheduler & sets how the learning rate is reported.
+type MetricsConfig struct {
+	// MetricLabels are the label values the learning rate is reported with. Once metrics are initialized they must
+	// match metrics.Config.LearningRateGaugeLabels, so nil only suits a gauge without labels; the constructor
+	// reports a mismatch.
+	MetricLabels []string
+}
+
+// baseLearningRate returns the learning rate of the optimizer at the time the scheduler is created, from which
+// the schedule is computed.
+func baseLearningRate(optimizer nn.Optimizer) (float64, error) {
+	if optimizer == nil {
+		return 0, fmt.Errorf("nil optimizer: %w", ErrInvalidConfig)
+	}
+
+	learningRate := optimizer.LearningRate()
+	if learningRate <= 0 {
+		return 0, fmt.Errorf("optimizer learning rate %f must be positive: %w", learningRate, ErrInvalidConfig)
+	}
+
+	return learningRate, nil
+}
+
+// setLearningRate reports the learning rate to the learning rate gauge & sets it on the optimizer. Metrics are
+// optional, so the gauge not being initialized is not an error. The gauge is observed first so that the optimizer
+// is left untouched if the labels don't match it.
+func setLearningRate(optimizer nn.Optimizer, learningRate float64, labels []string) error {
+	if err := metrics.ObserveLearningRateGauge(learningRate, labels); err != nil &&
+		!errors.Is(err, metrics.ErrMetricsNotInitialized) {
+		return fmt.Errorf("failed to observe learning rate: %w", err)
+	}
+
+	optimizer.SetLearningRate(learningRate)
+
+	return nil
+}
+
+// scheduled drives a schedule which is a pure function of the number of steps taken.
+type scheduled struct {
+	name      string
+	optimizer nn.Optimizer
+	schedule  func(step int) float64
+	labels    []string
+	steps     int
+	mu        sync.Mutex
+}
+
+func newScheduled(
+	name string,
+	optimizer nn.Optimizer,
+	schedule func(step int) float64,
+	labels []string,
+) (*scheduled, error) {
+	s := &scheduled{
+		name:      name,
+		optimizer: optimizer,
+		schedule:  schedule,
+		labels:    labels,
+	}
+
+	// The first step is taken at the learning rate of step zero, e.g the start of a warmup.
+	if err := setLearningRate(optimizer, schedule(0), labels); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *scheduled) Step(_ *nn.Value) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.steps++
+
+	return setLearningRate(s.optimizer, s.schedule(s.steps), s.labels)
+}
+
+func (s *scheduled) LearningRate() float64 {
+	return s.optimizer.LearningRate()
+}
+
+// Steps returns the number of steps taken so far.
+func (s *scheduled) Steps() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.steps
+}
+
+func (s *scheduled) MarshalState() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return marshalState(state{
+		Scheduler:    s.name,
+		LearningRate: s.optimizer.LearningRate(),
+		Steps:        s.steps,
+	})
+}
+
+func (s *scheduled) UnmarshalState(data []byte) error {
+	decoded, err := unmarshalState(data, s.name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := setLearningRate(s.optimizer, decoded.LearningRate, s.labels); err != nil {
+		return err
+	}
+	s.steps = decoded.Steps
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"grad2go/nn"
+	"math"
+)
+
+// state is the serialized form shared by every scheduler; the fields after Steps are only used by
+// ReduceOnPlateauScheduler.
+type state struct {
+	Scheduler    string  `json:"scheduler"`
+	LearningRate float64 `json:"learning_rate"`
+	Steps        int     `json:"steps"`
+	// Best is the best loss seen so far, or nil if there is none yet.
+	Best         *float64 `json:"best,omitempty"`
+	BadSteps     int      `json:"bad_steps,omitempty"`
+	CooldownLeft int      `json:"cooldown_left,omitempty"`
+}
+
+func marshalState(s state) ([]byte, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s state: %w", s.Scheduler, err)
+	}
+
+	return data, nil
+}
+
+func unmarshalState(data []byte, scheduler string) (*state, error) {
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s state: %w", scheduler, err)
+	}
+
+	switch {
+	case s.Scheduler != scheduler:
+		return nil, fmt.Errorf("state of %q cannot be restored into %q: %w", s.Scheduler, scheduler, ErrInvalidState)
+	case !(s.LearningRate >= 0) || math.IsInf(s.LearningRate, 1):
+		return nil, fmt.Errorf("learning rate %f must be finite & not negative: %w", s.LearningRate, ErrInvalidState)
+	case s.Steps < 0 || s.BadSteps < 0 || s.CooldownLeft < 0:
+		return nil, fmt.Errorf("negative steps: %w", ErrInvalidState)
+	}
+
+	return &s, nil
+}
+
+// checkpoint holds the optimizer & scheduler state of a training run, so that they're always restored together.
+type checkpoint struct {
+	Optimizer json.RawMessage `json:"optimizer"`
+	Scheduler json.RawMessage `json:"scheduler"`
+}
+
+// MarshalCheckpoint serializes the optimizer state of the network together with the state of the scheduler driving
+// its learning rate, so that a resumed run continues both from where they stopped. The optimizer must implement
+// nn.OptimizerStateMarshaler.
+func MarshalCheckpoint(net *nn.NeuralNetwork, scheduler Scheduler) ([]byte, error) {
+	optimizerState, err := net.MarshalOptimizerState()
+	if err != nil {
+		return nil, err
+	}
+
+	schedulerState, err := scheduler.MarshalState()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(checkpoint{Optimizer: optimizerState, Scheduler: schedulerState})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCheckpoint restores a checkpoint serialized by MarshalCheckpoint into a network & scheduler built with the
+// same configuration. The optimizer is restored first, so the learning rate ends up as set by the scheduler.
+func UnmarshalCheckpoint(data []byte, net *nn.NeuralNetwork, scheduler Scheduler) error {
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+
+	if err := net.UnmarshalOptimizerState(c.Optimizer); err != nil {
+		return err
+	}
+
+	return scheduler.UnmarshalState(c.Scheduler)
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"fmt"
+	"grad2go/nn"
+)
+
+var _ Scheduler = new(LinearWarmupScheduler)
+
+type LinearWarmupConfig struct {
+	// WarmupSteps is the number of steps taken to reach the base learning rate; must be positive.
+	WarmupSteps int
+	// StartFactor is the fraction of the base learning rate the warmup starts from; must be within (0, 1). It
+	// defaults to 1 / WarmupSteps if zero, so that the first step already trains rather than running at a learning
+	// rate of zero; a warmup cannot start from zero.
+	StartFactor float64
+	MetricsConfig
+}
+
+// LinearWarmupScheduler ramps the learning rate linearly from a fraction of the base learning rate up to the base
+// learning rate, where it then stays:
+//
+//	learning_rate = base * (start + (1 - start) * min(t, warmup_steps) / warmup_steps)
+type LinearWarmupScheduler struct {
+	*scheduled
+}
+
+func NewLinearWarmup(optimizer nn.Optimizer, cfg LinearWarmupConfig) (*LinearWarmupScheduler, error) {
+	base, err := baseLearningRate(optimizer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.WarmupSteps <= 0:
+		return nil, fmt.Errorf("warmup steps %d must be positive: %w", cfg.WarmupSteps, ErrInvalidConfig)
+	case !(cfg.StartFactor >= 0 && cfg.StartFactor < 1):
+		return nil, fmt.Errorf("start factor %f must be within (0, 1): %w", cfg.StartFactor, ErrInvalidConfig)
+	}
+
+	start := cfg.StartFactor
+	if start == 0 {
+		start = 1 / float64(cfg.WarmupSteps)
+	}
+
+	s, err := newScheduled("linear_warmup", optimizer, func(step int) float64 {
+		if step >= cfg.WarmupSteps {
+			return base
+		}
+
+		progress := float64(step) / float64(cfg.WarmupSteps)
+		return base * (start + (1-start)*progress)
+	}, cfg.MetricLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinearWarmupScheduler{s}, nil
+}